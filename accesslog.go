@@ -0,0 +1,108 @@
+package gemux
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// AccessLogConfig configures the access log middleware returned by AccessLog.
+type AccessLogConfig struct {
+	// Logger is the logger records are written to. If Logger is nil,
+	// slog.Default is used.
+	Logger *slog.Logger
+
+	// Levels maps status classes, such as 400 for 4xx status codes, to the
+	// level of the records of requests answered with them. Status classes
+	// that aren't in Levels are logged at slog.LevelInfo. If Levels is nil,
+	// 4xx responses are logged at slog.LevelWarn and 5xx responses at
+	// slog.LevelError.
+	Levels map[int]slog.Level
+}
+
+// defaultAccessLogLevels are the levels used when AccessLogConfig.Levels is nil.
+var defaultAccessLogLevels = map[int]slog.Level{
+	400: slog.LevelWarn,
+	500: slog.LevelError,
+}
+
+// AccessLog returns middleware to be added with Use that writes a "request"
+// record for each request once it has been served. Records have the method of
+// the request, the pattern of the matched route, which is empty if no route
+// matched, the path parameters, keyed by name if the route names them, the
+// status code, the number of bytes written to the response body, the duration
+// and the remote address. Requests whose handler panicked before writing the
+// header are logged with a 500 status code.
+func AccessLog(config AccessLogConfig) func(http.Handler) http.Handler {
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	levels := config.Levels
+	if levels == nil {
+		levels = defaultAccessLogLevels
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := newResponseWriter(w)
+			panicked := true
+
+			defer func() {
+				status := rw.statusCode()
+				if panicked && !rw.wroteHeader() {
+					// The mux replies with a 500 once it recovers.
+					status = http.StatusInternalServerError
+				}
+
+				level, ok := levels[status/100*100]
+				if !ok {
+					level = slog.LevelInfo
+				}
+
+				ctx := r.Context()
+				if !logger.Enabled(ctx, level) {
+					return
+				}
+
+				rt, _ := RouteFromContext(ctx)
+				logger.LogAttrs(ctx, level, "request",
+					slog.String("method", r.Method),
+					slog.String("pattern", rt.Pattern),
+					slog.Any("params", pathParameterAttrs(r, rt.ParamNames)),
+					slog.Int("status", status),
+					slog.Int64("bytes", rw.bytes),
+					slog.Duration("duration", time.Since(start)),
+					slog.String("remote_addr", r.RemoteAddr),
+				)
+			}()
+
+			next.ServeHTTP(rw.writer, r)
+			panicked = false
+		})
+	}
+}
+
+// pathParameterAttrs returns the path parameters of the request as a group of
+// attributes, keyed by name if names are given and by index otherwise.
+func pathParameterAttrs(r *http.Request, names []string) slog.Value {
+	var params []string
+	if value := routeValueFrom(r.Context()); value != nil {
+		params = value.params
+	}
+
+	attrs := make([]slog.Attr, len(params))
+	for i, param := range params {
+		key := strconv.Itoa(i)
+		if i < len(names) {
+			key = names[i]
+		}
+
+		attrs[i] = slog.String(key, param)
+	}
+
+	return slog.GroupValue(attrs...)
+}
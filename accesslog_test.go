@@ -0,0 +1,119 @@
+package gemux
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	cases := []struct {
+		name           string
+		requestURL     string
+		requestMethod  string
+		levels         map[int]slog.Level
+		expectedRecord map[string]interface{}
+	}{
+		{
+			name:          "named parameters",
+			requestURL:    "/posts/5/comments/2",
+			requestMethod: http.MethodGet,
+			expectedRecord: map[string]interface{}{
+				"level": "INFO", "msg": "request", "method": "GET", "pattern": "/posts/*/comments/*",
+				"params": map[string]interface{}{"postID": "5", "commentID": "2"},
+				"status": 200.0, "bytes": 7.0, "remote_addr": "192.0.2.1:1234",
+			},
+		},
+		{
+			name:          "positional parameters",
+			requestURL:    "/users/ada",
+			requestMethod: http.MethodGet,
+			expectedRecord: map[string]interface{}{
+				"level": "INFO", "msg": "request", "method": "GET", "pattern": "/users/*",
+				"params": map[string]interface{}{"0": "ada"},
+				"status": 200.0, "bytes": 4.0, "remote_addr": "192.0.2.1:1234",
+			},
+		},
+		{
+			name:          "not found",
+			requestURL:    "/posts",
+			requestMethod: http.MethodGet,
+			expectedRecord: map[string]interface{}{
+				"level": "WARN", "msg": "request", "method": "GET", "pattern": "",
+				"status": 404.0, "bytes": 19.0, "remote_addr": "192.0.2.1:1234",
+			},
+		},
+		{
+			name:          "panic",
+			requestURL:    "/panic",
+			requestMethod: http.MethodGet,
+			expectedRecord: map[string]interface{}{
+				"level": "ERROR", "msg": "request", "method": "GET", "pattern": "/panic",
+				"status": 500.0, "bytes": 0.0, "remote_addr": "192.0.2.1:1234",
+			},
+		},
+		{
+			name:          "custom levels",
+			requestURL:    "/users/ada",
+			requestMethod: http.MethodGet,
+			levels:        map[int]slog.Level{200: slog.LevelDebug},
+			expectedRecord: map[string]interface{}{
+				"level": "DEBUG", "msg": "request", "method": "GET", "pattern": "/users/*",
+				"params": map[string]interface{}{"0": "ada"},
+				"status": 200.0, "bytes": 4.0, "remote_addr": "192.0.2.1:1234",
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&logged, &slog.HandlerOptions{
+				Level: slog.LevelDebug,
+				ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+					if attr.Key == slog.TimeKey || attr.Key == "duration" {
+						return slog.Attr{}
+					}
+
+					return attr
+				},
+			}))
+
+			mux := new(ServeMux)
+			mux.Use(AccessLog(AccessLogConfig{Logger: logger, Levels: tt.levels}))
+			mux.Handle("/posts/*/comments/*", http.MethodGet, stringHandler("comment"), ParamNames("postID", "commentID"))
+			mux.Handle("/users/*", http.MethodGet, stringHandler("user"))
+			mux.Handle("/panic", http.MethodGet, panicHandler("oops"))
+
+			var panicLog bytes.Buffer
+			mux.ServeHTTP(httptest.NewRecorder(), newLoggedRequest(tt.requestMethod, tt.requestURL, &panicLog))
+
+			var record map[string]interface{}
+			if err := json.Unmarshal(logged.Bytes(), &record); err != nil {
+				t.Fatalf("expected one JSON record, got %q: %v", logged.String(), err)
+			}
+
+			if !reflect.DeepEqual(record, tt.expectedRecord) {
+				t.Errorf("expected record %v, got %v", tt.expectedRecord, record)
+			}
+		})
+	}
+}
+
+func TestAccessLogDisabledLevel(t *testing.T) {
+	var logged bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logged, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	mux := new(ServeMux)
+	mux.Use(AccessLog(AccessLogConfig{Logger: logger}))
+	mux.Handle("/", http.MethodGet, stringHandler("root"))
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if logged.Len() != 0 {
+		t.Errorf("expected nothing to be logged below the logger level, got %q", logged.String())
+	}
+}
@@ -0,0 +1,190 @@
+package gemux
+
+import (
+	"sort"
+	"strings"
+)
+
+// ConflictKind describes how a route conflicts with another.
+type ConflictKind int
+
+const (
+	// Shadowed means the route is registered under a static path segment
+	// that has a wildcard sibling, which always matches first, so the route
+	// is never routed to.
+	Shadowed ConflictKind = iota + 1
+
+	// Duplicate means the route was replaced by a later registration with the
+	// same pattern, method and predicates.
+	Duplicate
+
+	// Unreachable means the route is registered for a method, but a route
+	// for the "*" method with the same pattern and predicates always wins
+	// over it.
+	Unreachable
+
+	// TrailingSlash means the route was registered with a pattern that only
+	// differs from the pattern of another route by a trailing slash. Both
+	// patterns match the same requests.
+	TrailingSlash
+)
+
+// String returns a description of the kind of conflict, such as "shadowed".
+func (kind ConflictKind) String() string {
+	switch kind {
+	case Shadowed:
+		return "shadowed"
+	case Duplicate:
+		return "duplicate"
+	case Unreachable:
+		return "unreachable"
+	case TrailingSlash:
+		return "trailing slash"
+	default:
+		return "unknown conflict"
+	}
+}
+
+// Conflict describes a route that doesn't behave the way its registration
+// suggests, as found by Analyze.
+type Conflict struct {
+	// Kind is how the route conflicts with the other route.
+	Kind ConflictKind
+
+	// Route is the route that is shadowed, replaced, unreachable or
+	// registered with a trailing slash.
+	Route Route
+
+	// Other is the route that causes the conflict. For Shadowed conflicts,
+	// only its Pattern is set, to the wildcard pattern that shadows Route.
+	Other Route
+
+	// Description describes the conflict using the patterns the routes were
+	// registered with, such as "GET /posts/latest is shadowed by the wildcard
+	// of /posts/*".
+	Description string
+}
+
+// String returns the description of the conflict.
+func (c Conflict) String() string {
+	return c.Description
+}
+
+// Analyze inspects the routes registered on the mux and returns the conflicts
+// between them, in the order of Routes. It is meant to be called once every
+// route is registered, such as in a test or a startup self-check.
+func (mux *ServeMux) Analyze() []Conflict {
+	var conflicts []Conflict
+	mux.analyze("", "", &conflicts)
+	return conflicts
+}
+
+// analyze appends the conflicts of the mux and its descendants to conflicts.
+// If shadowedBy isn't empty, it is the wildcard pattern that shadows the mux.
+func (mux *ServeMux) analyze(pattern, shadowedBy string, conflicts *[]Conflict) {
+	cleaned := pattern
+	if cleaned == "" {
+		cleaned = "/"
+	}
+
+	routes := mux.routes()
+
+	if shadowedBy != "" {
+		for _, rt := range routes {
+			*conflicts = append(*conflicts, Conflict{
+				Kind:        Shadowed,
+				Route:       rt.describe(cleaned),
+				Other:       Route{Pattern: shadowedBy},
+				Description: rt.method + " " + rt.pattern + " is shadowed by the wildcard of " + shadowedBy,
+			})
+		}
+	}
+
+	for _, old := range mux.replaced {
+		winner := mux.routeWithPredicates(old.method, old.predicateKey())
+		*conflicts = append(*conflicts, Conflict{
+			Kind:  Duplicate,
+			Route: old.describe(cleaned),
+			Other: winner.describe(cleaned),
+			Description: winner.method + " " + winner.pattern + " replaces an earlier registration of " +
+				old.method + " " + old.pattern,
+		})
+	}
+
+	for _, rt := range routes {
+		if rt.method == "*" {
+			continue
+		}
+
+		if wildcard := mux.routeWithPredicates("*", rt.predicateKey()); wildcard != nil {
+			*conflicts = append(*conflicts, Conflict{
+				Kind:  Unreachable,
+				Route: rt.describe(cleaned),
+				Other: wildcard.describe(cleaned),
+				Description: rt.method + " " + rt.pattern + " is unreachable because " + wildcard.method + " " +
+					wildcard.pattern + " has the same predicates",
+			})
+		}
+	}
+
+	if len(routes) > 0 {
+		first := routes[0]
+		for _, rt := range routes[1:] {
+			if rt.pattern != first.pattern && strings.TrimSuffix(rt.pattern, "/") == strings.TrimSuffix(first.pattern, "/") {
+				*conflicts = append(*conflicts, Conflict{
+					Kind:  TrailingSlash,
+					Route: rt.describe(cleaned),
+					Other: first.describe(cleaned),
+					Description: rt.method + " " + rt.pattern + " and " + first.method + " " + first.pattern +
+						" only differ by a trailing slash",
+				})
+			}
+		}
+	}
+
+	if mux.wildcardChild != nil {
+		mux.wildcardChild.analyze(pattern+"/*", shadowedBy, conflicts)
+	}
+
+	segments := make([]string, 0, len(mux.children))
+	for segment := range mux.children {
+		segments = append(segments, segment)
+	}
+
+	sort.Strings(segments)
+	for _, segment := range segments {
+		childShadowedBy := shadowedBy
+		if childShadowedBy == "" && mux.wildcardChild != nil {
+			childShadowedBy = pattern + "/*"
+		}
+
+		mux.children[segment].analyze(pattern+"/"+segment, childShadowedBy, conflicts)
+	}
+}
+
+// routes returns the routes registered on the mux, in the order of Routes.
+func (mux *ServeMux) routes() []*route {
+	routes := append([]*route{}, mux.wildcardRoutes...)
+	for _, method := range mux.allowedMethods() {
+		routes = append(routes, mux.handlers[method]...)
+	}
+
+	return routes
+}
+
+// routeWithPredicates returns the route registered on the mux for method with
+// the given predicate key, or nil if there is none.
+func (mux *ServeMux) routeWithPredicates(method, key string) *route {
+	routes := mux.handlers[method]
+	if method == "*" {
+		routes = mux.wildcardRoutes
+	}
+
+	for _, rt := range routes {
+		if rt.predicateKey() == key {
+			return rt
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package gemux
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	cases := []struct {
+		name                 string
+		register             func(mux *ServeMux)
+		expectedConflicts    []ConflictKind
+		expectedDescriptions []string
+	}{
+		{
+			name: "no conflicts",
+			register: func(mux *ServeMux) {
+				mux.Handle("/posts", http.MethodGet, stringHandler("a"))
+				mux.Handle("/posts", http.MethodGet, stringHandler("b"), Header("Accept", "application/json"))
+				mux.Handle("/posts/*", http.MethodGet, stringHandler("c"))
+				mux.Handle("/posts/*", "*", stringHandler("d"), Header("X-Debug", "1"))
+			},
+		},
+		{
+			name: "shadowed",
+			register: func(mux *ServeMux) {
+				mux.Handle("/posts/*", http.MethodGet, stringHandler("a"))
+				mux.Handle("/posts/latest", http.MethodGet, stringHandler("b"))
+				mux.Handle("/posts/latest/comments", http.MethodGet, stringHandler("c"))
+			},
+			expectedConflicts: []ConflictKind{Shadowed, Shadowed},
+			expectedDescriptions: []string{
+				"GET /posts/latest is shadowed by the wildcard of /posts/*",
+				"GET /posts/latest/comments is shadowed by the wildcard of /posts/*",
+			},
+		},
+		{
+			name: "shadowed by wildcard without routes",
+			register: func(mux *ServeMux) {
+				mux.Handle("/*/comments", http.MethodGet, stringHandler("a"))
+				mux.Handle("/posts", http.MethodGet, stringHandler("b"))
+			},
+			expectedConflicts:    []ConflictKind{Shadowed},
+			expectedDescriptions: []string{"GET /posts is shadowed by the wildcard of /*"},
+		},
+		{
+			name: "duplicate",
+			register: func(mux *ServeMux) {
+				mux.Handle("/posts", http.MethodGet, stringHandler("a"), Query("page", "1"))
+				mux.Handle("/posts", http.MethodGet, stringHandler("b"), Query("page", "1"))
+			},
+			expectedConflicts:    []ConflictKind{Duplicate},
+			expectedDescriptions: []string{"GET /posts replaces an earlier registration of GET /posts"},
+		},
+		{
+			name: "unreachable",
+			register: func(mux *ServeMux) {
+				mux.Handle("/posts", "*", stringHandler("a"))
+				mux.Handle("/posts", http.MethodGet, stringHandler("b"))
+			},
+			expectedConflicts:    []ConflictKind{Unreachable},
+			expectedDescriptions: []string{"GET /posts is unreachable because * /posts has the same predicates"},
+		},
+		{
+			name: "trailing slash",
+			register: func(mux *ServeMux) {
+				mux.Handle("/posts", http.MethodGet, stringHandler("a"))
+				mux.Handle("/posts/", http.MethodPost, stringHandler("b"))
+			},
+			expectedConflicts:    []ConflictKind{TrailingSlash},
+			expectedDescriptions: []string{"POST /posts/ and GET /posts only differ by a trailing slash"},
+		},
+		{
+			name: "duplicate with trailing slash",
+			register: func(mux *ServeMux) {
+				mux.Handle("/posts", http.MethodGet, stringHandler("a"))
+				mux.Handle("/posts/", http.MethodGet, stringHandler("b"))
+			},
+			expectedConflicts:    []ConflictKind{Duplicate},
+			expectedDescriptions: []string{"GET /posts/ replaces an earlier registration of GET /posts"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := new(ServeMux)
+			tt.register(mux)
+
+			conflicts := mux.Analyze()
+			if len(conflicts) != len(tt.expectedConflicts) {
+				t.Fatalf("expected %d conflicts, got %v", len(tt.expectedConflicts), conflicts)
+			}
+
+			for i, conflict := range conflicts {
+				if conflict.Kind != tt.expectedConflicts[i] {
+					t.Errorf("expected conflict %d to be %s, got %s", i, tt.expectedConflicts[i], conflict.Kind)
+				}
+
+				if conflict.String() != tt.expectedDescriptions[i] {
+					t.Errorf("expected description %q, got %q", tt.expectedDescriptions[i], conflict)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeRoutes(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Handle("/posts/*", http.MethodGet, stringHandler("a"))
+	mux.Handle("/posts/latest", http.MethodGet, stringHandler("b"))
+	mux.Handle("/posts/*", http.MethodGet, stringHandler("c"))
+
+	conflicts := mux.Analyze()
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %v", conflicts)
+	}
+
+	duplicate := conflicts[0]
+	if duplicate.Route.Pattern != "/posts/*" || handlerBody(duplicate.Route.Handler) != "a" ||
+		handlerBody(duplicate.Other.Handler) != "c" {
+		t.Errorf("expected duplicate route a replaced by c, got %+v", duplicate)
+	}
+
+	shadowed := conflicts[1]
+	if shadowed.Route.Pattern != "/posts/latest" || shadowed.Other.Pattern != "/posts/*" || shadowed.Other.Handler != nil {
+		t.Errorf("expected /posts/latest shadowed by /posts/*, got %+v", shadowed)
+	}
+}
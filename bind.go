@@ -0,0 +1,323 @@
+package gemux
+
+import (
+	"encoding"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BindError describes every field Bind couldn't fill.
+type BindError struct {
+	Fields []FieldError
+}
+
+// FieldError describes a field Bind couldn't fill.
+type FieldError struct {
+	// Field is the name of the struct field, such as "Limit".
+	Field string
+
+	// Source is where the value of the field comes from, "path" or "query".
+	Source string
+
+	// Name is the name of the parameter in its source, such as "limit" or
+	// "0".
+	Name string
+
+	// Reason describes why the field couldn't be filled, such as "is
+	// required" or "must be an integer".
+	Reason string
+}
+
+// Error returns a description of the field error, such as `query parameter
+// "limit" must be an integer`.
+func (err FieldError) Error() string {
+	return err.Source + " parameter " + strconv.Quote(err.Name) + " " + err.Reason
+}
+
+// Error returns a description of every field error.
+func (err *BindError) Error() string {
+	messages := make([]string, len(err.Fields))
+	for i, field := range err.Fields {
+		messages[i] = field.Error()
+	}
+
+	return "gemux: invalid parameters: " + strings.Join(messages, "; ")
+}
+
+// StatusCode returns http.StatusBadRequest, so JSON handlers reply to a
+// BindError with a 400.
+func (err *BindError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Bind fills the fields of the struct v points to from the path parameters and
+// URL query of the request, as given by their tags. A `path:"0"` tag is filled
+// with the path parameter at that index, a `path:"postID"` tag with the path
+// parameter of that name, as named with ParamNames, and a `query:"limit"` tag
+// with the query parameter of that name. Adding ",required" to the tag, such as
+// `query:"limit,required"`, makes Bind fail if the parameter is missing or
+// empty, and a `default:"20"` tag gives the value used when it is. Fields of
+// embedded structs are filled too.
+//
+// Fields can be strings, booleans, integers, floats, time.Durations, types
+// implementing encoding.TextUnmarshaler, or pointers to those, which are left
+// nil if the parameter is missing. Fields filled from the query can also be
+// slices of those, filled with every value of the parameter.
+//
+// If any field can't be filled, a *BindError listing every bad field is
+// returned. Bind panics if v isn't a pointer to a struct, or if it has a tagged
+// field that is unexported or of an unsupported type, whether or not the
+// request has a value for it.
+func Bind(r *http.Request, v interface{}) error {
+	if v == nil {
+		panic("gemux: Bind requires a non-nil pointer to a struct, got nil")
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		panic("gemux: Bind requires a non-nil pointer to a struct, got " + reflect.TypeOf(v).String())
+	}
+
+	checkBindable(rv.Elem().Type())
+
+	bindErr := new(BindError)
+	bindStruct(r, rv.Elem(), r.URL.Query(), bindErr)
+
+	if len(bindErr.Fields) > 0 {
+		return bindErr
+	}
+
+	return nil
+}
+
+// bindStruct fills the tagged fields of the struct sv, appending a FieldError
+// to bindErr for each field that can't be filled.
+func bindStruct(r *http.Request, sv reflect.Value, query map[string][]string, bindErr *BindError) {
+	st := sv.Type()
+
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		fv := sv.Field(i)
+
+		source, tag := "path", field.Tag.Get("path")
+		if tag == "" {
+			source, tag = "query", field.Tag.Get("query")
+		}
+
+		if tag == "" {
+			if field.Anonymous && fv.Kind() == reflect.Struct {
+				bindStruct(r, fv, query, bindErr)
+			}
+
+			continue
+		}
+
+		name, options := tag, ""
+		if comma := strings.IndexByte(tag, ','); comma >= 0 {
+			name, options = tag[:comma], tag[comma+1:]
+		}
+
+		var values []string
+		if source == "path" {
+			if param := bindPathParameter(r, name); param != "" {
+				values = []string{param}
+			}
+		} else if len(query[name]) > 1 || len(query[name]) == 1 && query[name][0] != "" {
+			values = query[name]
+		}
+
+		if values == nil {
+			if def, ok := field.Tag.Lookup("default"); ok {
+				values = []string{def}
+			} else if options == "required" {
+				bindErr.Fields = append(bindErr.Fields, FieldError{
+					Field:  field.Name,
+					Source: source,
+					Name:   name,
+					Reason: "is required",
+				})
+				continue
+			}
+		}
+
+		if values == nil {
+			continue
+		}
+
+		if reason := setField(fv, values, source == "query"); reason != "" {
+			bindErr.Fields = append(bindErr.Fields, FieldError{
+				Field:  field.Name,
+				Source: source,
+				Name:   name,
+				Reason: reason,
+			})
+		}
+	}
+}
+
+// checkBindable panics if a tagged field of the struct type st, or of a struct
+// embedded in it, is unexported or of a type Bind can't set.
+func checkBindable(st reflect.Type) {
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+
+		slices := false
+		if field.Tag.Get("path") == "" {
+			if field.Tag.Get("query") == "" {
+				if field.Anonymous && field.Type.Kind() == reflect.Struct {
+					checkBindable(field.Type)
+				}
+
+				continue
+			}
+
+			slices = true
+		}
+
+		if field.PkgPath != "" {
+			panic("gemux: Bind can't set unexported field " + field.Name)
+		}
+
+		if !bindable(field.Type, slices) {
+			panic("gemux: Bind can't set field " + field.Name + " of type " + field.Type.String())
+		}
+	}
+}
+
+// bindable reports whether setField can set a value of type t, with slices
+// filled from every value if slices are allowed.
+func bindable(t reflect.Type, slices bool) bool {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) || t == durationType {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Ptr:
+		return bindable(t.Elem(), false)
+	case reflect.Slice:
+		return slices && bindable(t.Elem(), false)
+	}
+
+	return false
+}
+
+// bindPathParameter returns the path parameter with the given index or name.
+func bindPathParameter(r *http.Request, name string) string {
+	if n, err := strconv.Atoi(name); err == nil {
+		return PathParameter(r.Context(), n)
+	}
+
+	return NamedPathParameter(r.Context(), name)
+}
+
+// setField sets fv from values, which are all used if fv is a slice and
+// slices are allowed, otherwise only the last value is used. If a value can't
+// be converted, the reason is returned.
+func setField(fv reflect.Value, values []string, slices bool) string {
+	if slices && fv.Kind() == reflect.Slice && !fv.Type().Implements(textUnmarshalerType) &&
+		!reflect.PtrTo(fv.Type()).Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, value := range values {
+			if reason := setValue(slice.Index(i), value); reason != "" {
+				return reason
+			}
+		}
+
+		fv.Set(slice)
+		return ""
+	}
+
+	return setValue(fv, values[len(values)-1])
+}
+
+// setValue sets fv from value, returning the reason if value can't be
+// converted.
+func setValue(fv reflect.Value, value string) string {
+	if fv.Kind() == reflect.Ptr {
+		elem := reflect.New(fv.Type().Elem())
+		if reason := setValue(elem.Elem(), value); reason != "" {
+			return reason
+		}
+
+		fv.Set(elem)
+		return ""
+	}
+
+	if unmarshaler, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalText([]byte(value)); err != nil {
+			return "is invalid: " + err.Error()
+		}
+
+		return ""
+	}
+
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return "must be a duration"
+		}
+
+		fv.SetInt(int64(d))
+		return ""
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "must be a boolean"
+		}
+
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fv.Type().Bits())
+		if err != nil {
+			return "must be an integer" + rangeReason(err)
+		}
+
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fv.Type().Bits())
+		if err != nil {
+			return "must be a non-negative integer" + rangeReason(err)
+		}
+
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fv.Type().Bits())
+		if err != nil {
+			return "must be a number" + rangeReason(err)
+		}
+
+		fv.SetFloat(f)
+	default:
+		panic("gemux: Bind can't set fields of type " + fv.Type().String())
+	}
+
+	return ""
+}
+
+// rangeReason returns a suffix for conversion errors caused by values out of
+// range.
+func rangeReason(err error) string {
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		return " in range"
+	}
+
+	return ""
+}
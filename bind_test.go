@@ -0,0 +1,195 @@
+package gemux
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type paginationParams struct {
+	Limit  int    `query:"limit" default:"20"`
+	Cursor string `query:"cursor"`
+}
+
+type listCommentsParams struct {
+	paginationParams
+	PostID    int64         `path:"postID"`
+	Index     string        `path:"1"`
+	Sort      *string       `query:"sort"`
+	Tags      []string      `query:"tag"`
+	IDs       []uint        `query:"id"`
+	Verbose   bool          `query:"verbose"`
+	Score     float64       `query:"score"`
+	Timeout   time.Duration `query:"timeout" default:"1s"`
+	Client    net.IP        `query:"client"`
+	Author    string        `query:"author,required"`
+	Untagged  string
+	Unchanged string `query:"unchanged"`
+}
+
+func TestBind(t *testing.T) {
+	sort := "newest"
+
+	cases := []struct {
+		name           string
+		requestURL     string
+		expectedParams listCommentsParams
+		expectedErrors []FieldError
+	}{
+		{
+			name:       "every field",
+			requestURL: "/posts/5/comments/7?limit=10&cursor=abc&sort=newest&tag=a&tag=b&id=1&id=2&verbose=true&score=1.5&timeout=2m&client=192.0.2.1&author=ada",
+			expectedParams: listCommentsParams{
+				paginationParams: paginationParams{Limit: 10, Cursor: "abc"},
+				PostID:           5,
+				Index:            "7",
+				Sort:             &sort,
+				Tags:             []string{"a", "b"},
+				IDs:              []uint{1, 2},
+				Verbose:          true,
+				Score:            1.5,
+				Timeout:          2 * time.Minute,
+				Client:           net.ParseIP("192.0.2.1"),
+				Author:           "ada",
+				Unchanged:        "kept",
+			},
+		},
+		{
+			name:       "defaults",
+			requestURL: "/posts/5/comments/7?author=ada&limit=",
+			expectedParams: listCommentsParams{
+				paginationParams: paginationParams{Limit: 20},
+				PostID:           5,
+				Index:            "7",
+				Timeout:          time.Second,
+				Author:           "ada",
+				Unchanged:        "kept",
+			},
+		},
+		{
+			name:       "every bad field",
+			requestURL: "/posts/five/comments/7?limit=ten&id=1&id=-2&verbose=maybe&timeout=soon&client=nope&limit=x",
+			expectedErrors: []FieldError{
+				{Field: "Limit", Source: "query", Name: "limit", Reason: "must be an integer"},
+				{Field: "PostID", Source: "path", Name: "postID", Reason: "must be an integer"},
+				{Field: "IDs", Source: "query", Name: "id", Reason: "must be a non-negative integer"},
+				{Field: "Verbose", Source: "query", Name: "verbose", Reason: "must be a boolean"},
+				{Field: "Timeout", Source: "query", Name: "timeout", Reason: "must be a duration"},
+				{Field: "Client", Source: "query", Name: "client", Reason: "is invalid: invalid IP address: nope"},
+				{Field: "Author", Source: "query", Name: "author", Reason: "is required"},
+			},
+		},
+		{
+			name:       "out of range",
+			requestURL: "/posts/99999999999999999999/comments/7?author=ada",
+			expectedErrors: []FieldError{
+				{Field: "PostID", Source: "path", Name: "postID", Reason: "must be an integer in range"},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			params := listCommentsParams{Unchanged: "kept"}
+
+			mux := new(ServeMux)
+			mux.Handle("/posts/*/comments/*", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				err = Bind(r, &params)
+			}), ParamNames("postID", "commentID"))
+
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.requestURL, nil))
+
+			if tt.expectedErrors != nil {
+				bindErr, ok := err.(*BindError)
+				if !ok {
+					t.Fatalf("expected *BindError, got %v", err)
+				}
+
+				if !reflect.DeepEqual(bindErr.Fields, tt.expectedErrors) {
+					t.Errorf("expected errors %+v, got %+v", tt.expectedErrors, bindErr.Fields)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("did not expect error binding: %v", err)
+			}
+
+			if !reflect.DeepEqual(params, tt.expectedParams) {
+				t.Errorf("expected params %+v, got %+v", tt.expectedParams, params)
+			}
+		})
+	}
+}
+
+func TestBindError(t *testing.T) {
+	err := &BindError{Fields: []FieldError{
+		{Field: "Limit", Source: "query", Name: "limit", Reason: "must be an integer"},
+		{Field: "PostID", Source: "path", Name: "postID", Reason: "is required"},
+	}}
+
+	expected := `gemux: invalid parameters: query parameter "limit" must be an integer; path parameter "postID" is required`
+	if err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+
+	rw := httptest.NewRecorder()
+	JSON(func(ctx context.Context, req struct{}) (struct{}, error) {
+		return struct{}{}, err
+	}).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("expected JSON handlers to reply with 400, got %d", rw.Code)
+	}
+}
+
+func TestBindPanics(t *testing.T) {
+	type embedded struct {
+		C complex64 `query:"c"`
+	}
+
+	cases := []struct {
+		name string
+		v    interface{}
+	}{
+		{"nil", nil},
+		{"nil pointer", (*struct{})(nil)},
+		{"not a pointer", struct{}{}},
+		{"not a struct", new(string)},
+		{"unsupported type", &struct {
+			Values map[string]string `query:"values"`
+		}{}},
+		{"unsupported type without a value", &struct {
+			C complex64 `query:"c"`
+		}{}},
+		{"unsupported type in embedded struct", &struct {
+			embedded
+		}{}},
+		{"slice in path", &struct {
+			IDs []int `path:"0"`
+		}{}},
+		{"unexported field", &struct {
+			limit int `query:"limit"`
+		}{}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				v := recover()
+				if message, ok := v.(string); !ok || !strings.HasPrefix(message, "gemux: ") {
+					t.Errorf("expected gemux panic, got %v", v)
+				}
+			}()
+
+			_ = Bind(httptest.NewRequest(http.MethodGet, "/?values=a", nil), tt.v)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package gemux
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// MaxBodyBytes returns a RouteOption that limits the size of request bodies
+// the route accepts to n bytes, overriding the MaxBodyBytes of the mux. A
+// negative n removes the limit for the route.
+//
+// Requests with a larger Content-Length are answered with the
+// RequestEntityTooLargeHandler of the mux without calling the route's handler.
+// Otherwise the body is wrapped with http.MaxBytesReader, and if the handler
+// reads past the limit without writing a response, the mux replies with the
+// RequestEntityTooLargeHandler after the handler returns.
+func MaxBodyBytes(n int64) RouteOption {
+	return func(rt *route) {
+		rt.maxBodyBytes = n
+	}
+}
+
+// bodyLimit returns the maximum body size for rt, or a non-positive number if
+// the body size isn't limited.
+func (mux *ServeMux) bodyLimit(rt *route) int64 {
+	if rt.maxBodyBytes != 0 {
+		return rt.maxBodyBytes
+	}
+
+	return mux.MaxBodyBytes
+}
+
+// requestEntityTooLargeHandler returns the mux RequestEntityTooLargeHandler if
+// there is one, otherwise RequestEntityTooLargeHandler.
+func (mux *ServeMux) requestEntityTooLargeHandler() http.Handler {
+	if mux.RequestEntityTooLargeHandler != nil {
+		return mux.RequestEntityTooLargeHandler
+	}
+
+	return RequestEntityTooLargeHandler()
+}
+
+// RequestEntityTooLargeHandler returns a simple request handler that replies to
+// each request with a "413 request entity too large" reply and writes the 413
+// status code.
+func RequestEntityTooLargeHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "413 request entity too large", http.StatusRequestEntityTooLarge)
+	})
+}
+
+// serveWithBodyLimit serves the request with handler, limiting the request body
+// to limit bytes.
+func (mux *ServeMux) serveWithBodyLimit(w http.ResponseWriter, r *http.Request, handler http.Handler, limit int64) {
+	if r.ContentLength > limit {
+		mux.requestEntityTooLargeHandler().ServeHTTP(w, r)
+		return
+	}
+
+	if r.Body == nil {
+		handler.ServeHTTP(w, r)
+		return
+	}
+
+	rw := newResponseWriter(w)
+	body := &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, limit)}
+
+	limited := new(http.Request)
+	*limited = *r
+	limited.Body = body
+
+	handler.ServeHTTP(rw.writer, limited)
+
+	if body.exceeded && !rw.wroteHeader() {
+		mux.requestEntityTooLargeHandler().ServeHTTP(rw.writer, r)
+	}
+}
+
+// limitedBody records whether reading a body wrapped with http.MaxBytesReader
+// failed because the body was larger than the limit.
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+// Read reads from the wrapped body, recording whether http.MaxBytesReader
+// failed because the body is larger than the limit.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		b.exceeded = true
+	}
+
+	return n, err
+}
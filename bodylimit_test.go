@@ -0,0 +1,166 @@
+package gemux
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func readBodyHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return
+	}
+
+	_, _ = w.Write(body)
+}
+
+// errorReader is a reader that always fails with err.
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	cases := []struct {
+		name                 string
+		muxLimit             int64
+		opts                 []RouteOption
+		handler              http.HandlerFunc
+		body                 string
+		unknownLength        bool
+		bodyErr              error
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "under route limit",
+			opts:                 []RouteOption{MaxBodyBytes(5)},
+			handler:              readBodyHandler,
+			body:                 "abcde",
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "abcde",
+		},
+		{
+			name:                 "content length over route limit",
+			opts:                 []RouteOption{MaxBodyBytes(5)},
+			handler:              readBodyHandler,
+			body:                 "abcdef",
+			expectedResponseCode: http.StatusRequestEntityTooLarge,
+			expectedResponseBody: "413 request entity too large\n",
+		},
+		{
+			name:                 "streamed body over route limit",
+			opts:                 []RouteOption{MaxBodyBytes(5)},
+			handler:              readBodyHandler,
+			body:                 "abcdef",
+			unknownLength:        true,
+			expectedResponseCode: http.StatusRequestEntityTooLarge,
+			expectedResponseBody: "413 request entity too large\n",
+		},
+		{
+			name:     "handler response to streamed body over limit",
+			muxLimit: 5,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if _, err := ioutil.ReadAll(r.Body); err != nil {
+					http.Error(w, "too big", http.StatusBadRequest)
+				}
+			},
+			body:                 "abcdef",
+			unknownLength:        true,
+			expectedResponseCode: http.StatusBadRequest,
+			expectedResponseBody: "too big\n",
+		},
+		{
+			name:                 "body truncated at route limit",
+			opts:                 []RouteOption{MaxBodyBytes(5)},
+			handler:              readBodyHandler,
+			body:                 "abcde",
+			unknownLength:        true,
+			bodyErr:              io.ErrUnexpectedEOF,
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "",
+		},
+		{
+			name:                 "over mux limit",
+			muxLimit:             3,
+			handler:              readBodyHandler,
+			body:                 "abcd",
+			expectedResponseCode: http.StatusRequestEntityTooLarge,
+			expectedResponseBody: "413 request entity too large\n",
+		},
+		{
+			name:                 "route limit overrides mux limit",
+			muxLimit:             3,
+			opts:                 []RouteOption{MaxBodyBytes(10)},
+			handler:              readBodyHandler,
+			body:                 "abcd",
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "abcd",
+		},
+		{
+			name:                 "route without limit",
+			muxLimit:             3,
+			opts:                 []RouteOption{MaxBodyBytes(-1)},
+			handler:              readBodyHandler,
+			body:                 "abcd",
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "abcd",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := &ServeMux{MaxBodyBytes: tt.muxLimit}
+			mux.Handle("/upload", http.MethodPost, tt.handler, tt.opts...)
+
+			var body io.Reader = strings.NewReader(tt.body)
+			if tt.bodyErr != nil {
+				body = io.MultiReader(body, errorReader{tt.bodyErr})
+			}
+
+			if tt.unknownLength {
+				body = ioutil.NopCloser(body)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/upload", body)
+			if tt.unknownLength {
+				req.ContentLength = -1
+			}
+
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, req)
+
+			if rw.Code != tt.expectedResponseCode {
+				t.Errorf("expected response code %d, got %d", tt.expectedResponseCode, rw.Code)
+			}
+
+			if body := rw.Body.String(); body != tt.expectedResponseBody {
+				t.Errorf("expected response body %q, got %q", tt.expectedResponseBody, body)
+			}
+		})
+	}
+}
+
+func TestMaxBodyBytesCustomHandler(t *testing.T) {
+	mux := &ServeMux{
+		MaxBodyBytes: 1,
+		RequestEntityTooLargeHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"error":"too large"}`, http.StatusRequestEntityTooLarge)
+		}),
+	}
+	mux.Handle("/upload", http.MethodPost, http.HandlerFunc(readBodyHandler))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("ab")))
+
+	if body := rw.Body.String(); body != "{\"error\":\"too large\"}\n" {
+		t.Errorf("expected custom response body, got %q", body)
+	}
+}
@@ -0,0 +1,219 @@
+package gemux
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures cross-origin resource sharing for a ServeMux or for
+// individual routes. Preflight requests are answered by the mux, with the
+// allowed methods taken from the routes registered for the path.
+type CORSConfig struct {
+	// AllowedOrigins are the origins allowed to make cross-origin requests,
+	// such as "https://example.com". An origin of "*" allows any origin, and a
+	// single "*" in an origin matches any part of it, so
+	// "https://*.example.com" allows any subdomain of example.com.
+	AllowedOrigins []string
+
+	// AllowedHeaders are the request headers allowed in cross-origin requests.
+	// A header of "*" allows any header.
+	AllowedHeaders []string
+
+	// ExposedHeaders are the response headers made available to scripts making
+	// cross-origin requests.
+	ExposedHeaders []string
+
+	// AllowCredentials allows cross-origin requests to include credentials
+	// such as cookies. It is ignored if AllowedOrigins contains "*", since
+	// echoing any origin with credentials allowed would let every site make
+	// authenticated requests on behalf of its visitors. List the trusted
+	// origins instead.
+	AllowCredentials bool
+
+	// MaxAge is how long the result of a preflight request can be cached. If
+	// MaxAge is zero, the Access-Control-Max-Age header isn't sent.
+	MaxAge time.Duration
+}
+
+// CORS returns a RouteOption that configures cross-origin resource sharing for
+// the route, overriding the CORS configuration of the mux.
+func CORS(config *CORSConfig) RouteOption {
+	return func(rt *route) {
+		rt.cors = config
+	}
+}
+
+// corsConfig returns the CORS configuration for rt, or nil if cross-origin
+// requests aren't configured for it.
+func (mux *ServeMux) corsConfig(rt *route) *CORSConfig {
+	if rt != nil && rt.cors != nil {
+		return rt.cors
+	}
+
+	return mux.CORS
+}
+
+// isPreflight reports whether the request is a CORS preflight request.
+func isPreflight(r *http.Request, method string) bool {
+	return method == http.MethodOptions && r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// servePreflight answers a preflight request for node, using the CORS
+// configuration of the routes for the requested method. It reports whether
+// CORS is configured for them, and otherwise doesn't write a response.
+func (mux *ServeMux) servePreflight(w http.ResponseWriter, r *http.Request, node *ServeMux) bool {
+	requestedMethod := r.Header.Get("Access-Control-Request-Method")
+	if mux.NormalizeMethods {
+		requestedMethod = strings.ToUpper(requestedMethod)
+	}
+
+	var rt *route
+	if routes := node.handlers[requestedMethod]; len(routes) > 0 {
+		rt = routes[0]
+	} else if len(node.wildcardRoutes) > 0 {
+		rt = node.wildcardRoutes[0]
+	}
+
+	config := mux.corsConfig(rt)
+	if config == nil {
+		return false
+	}
+
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+
+	origin := r.Header.Get("Origin")
+	if rt == nil || !config.allowsOrigin(origin) {
+		w.WriteHeader(http.StatusNoContent)
+		return true
+	}
+
+	config.setOriginHeaders(header, origin)
+
+	methods := node.allowedMethods()
+	if len(node.wildcardRoutes) > 0 && len(node.handlers[requestedMethod]) == 0 {
+		methods = append(methods, requestedMethod)
+	}
+
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+	if requestedHeaders := r.Header.Get("Access-Control-Request-Headers"); requestedHeaders != "" {
+		if allowed := config.allowedHeaders(requestedHeaders); allowed != "" {
+			header.Set("Access-Control-Allow-Headers", allowed)
+		}
+	}
+
+	if config.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge/time.Second)))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// setCORSHeaders sets the CORS headers of the response to an actual
+// cross-origin request served by rt.
+func (mux *ServeMux) setCORSHeaders(w http.ResponseWriter, r *http.Request, rt *route) {
+	config := mux.corsConfig(rt)
+	if config == nil {
+		return
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+
+	header := w.Header()
+	header.Add("Vary", "Origin")
+
+	if !config.allowsOrigin(origin) {
+		return
+	}
+
+	config.setOriginHeaders(header, origin)
+
+	if len(config.ExposedHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(config.ExposedHeaders, ", "))
+	}
+}
+
+// setOriginHeaders sets the Access-Control-Allow-Origin and
+// Access-Control-Allow-Credentials headers for an allowed origin. Credentials
+// are never allowed when any origin is.
+func (config *CORSConfig) setOriginHeaders(header http.Header, origin string) {
+	if config.allowsAnyOrigin() {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+
+	header.Set("Access-Control-Allow-Origin", origin)
+
+	if config.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allowsAnyOrigin reports whether AllowedOrigins contains "*".
+func (config *CORSConfig) allowsAnyOrigin() bool {
+	for _, allowed := range config.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowsOrigin reports whether origin is allowed by AllowedOrigins.
+func (config *CORSConfig) allowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+
+	for _, allowed := range config.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+
+		i := strings.IndexByte(allowed, '*')
+		if i < 0 {
+			if allowed == origin {
+				return true
+			}
+
+			continue
+		}
+
+		prefix, suffix := allowed[:i], allowed[i+1:]
+		if len(origin) >= len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) &&
+			strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedHeaders returns the comma separated headers out of requestedHeaders
+// that are allowed by AllowedHeaders.
+func (config *CORSConfig) allowedHeaders(requestedHeaders string) string {
+	var allowed []string
+
+	for _, requested := range strings.Split(requestedHeaders, ",") {
+		requested = strings.TrimSpace(requested)
+		if requested == "" {
+			continue
+		}
+
+		for _, header := range config.AllowedHeaders {
+			if header == "*" || strings.EqualFold(header, requested) {
+				allowed = append(allowed, requested)
+				break
+			}
+		}
+	}
+
+	return strings.Join(allowed, ", ")
+}
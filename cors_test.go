@@ -0,0 +1,237 @@
+package gemux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORSPreflight(t *testing.T) {
+	config := &CORSConfig{
+		AllowedOrigins:   []string{"https://example.com", "https://*.example.org"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+
+	cases := []struct {
+		name                 string
+		origin               string
+		requestMethod        string
+		requestHeaders       string
+		requestURL           string
+		expectedResponseCode int
+		expectedHeader       http.Header
+	}{
+		{
+			name:                 "allowed",
+			origin:               "https://example.com",
+			requestMethod:        http.MethodPut,
+			requestHeaders:       "content-type, X-Other",
+			requestURL:           "/posts/1",
+			expectedResponseCode: http.StatusNoContent,
+			expectedHeader: http.Header{
+				"Access-Control-Allow-Origin":      {"https://example.com"},
+				"Access-Control-Allow-Credentials": {"true"},
+				"Access-Control-Allow-Methods":     {"DELETE, GET, PROPFIND, PUT"},
+				"Access-Control-Allow-Headers":     {"content-type"},
+				"Access-Control-Max-Age":           {"600"},
+				"Vary":                             {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+			},
+		},
+		{
+			name:                 "wildcard subdomain",
+			origin:               "https://api.example.org",
+			requestMethod:        http.MethodGet,
+			requestURL:           "/posts/1",
+			expectedResponseCode: http.StatusNoContent,
+			expectedHeader: http.Header{
+				"Access-Control-Allow-Origin":      {"https://api.example.org"},
+				"Access-Control-Allow-Credentials": {"true"},
+				"Access-Control-Allow-Methods":     {"DELETE, GET, PROPFIND, PUT"},
+				"Access-Control-Max-Age":           {"600"},
+				"Vary":                             {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+			},
+		},
+		{
+			name:                 "disallowed origin",
+			origin:               "https://evil.com",
+			requestMethod:        http.MethodGet,
+			requestURL:           "/posts/1",
+			expectedResponseCode: http.StatusNoContent,
+			expectedHeader: http.Header{
+				"Vary": {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+			},
+		},
+		{
+			name:                 "explicit options route",
+			origin:               "https://example.com",
+			requestMethod:        http.MethodGet,
+			requestURL:           "/options",
+			expectedResponseCode: http.StatusOK,
+			expectedHeader: http.Header{
+				"Access-Control-Allow-Origin":      {"https://example.com"},
+				"Access-Control-Allow-Credentials": {"true"},
+				"Content-Type":                     {"text/plain; charset=utf-8"},
+				"Vary":                             {"Origin"},
+			},
+		},
+		{
+			name:                 "not found",
+			origin:               "https://example.com",
+			requestMethod:        http.MethodGet,
+			requestURL:           "/missing",
+			expectedResponseCode: http.StatusNotFound,
+			expectedHeader: http.Header{
+				"Content-Type":           {"text/plain; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := &ServeMux{CORS: config}
+			mux.HandleMethods("/posts/*", []string{http.MethodGet, http.MethodPut, http.MethodDelete, "PROPFIND"},
+				stringHandler("a"))
+			mux.HandleMethods("/options", []string{http.MethodGet, http.MethodOptions}, stringHandler("b"))
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodOptions, tt.requestURL, nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+			if tt.requestHeaders != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.requestHeaders)
+			}
+
+			mux.ServeHTTP(rw, req)
+
+			if rw.Code != tt.expectedResponseCode {
+				t.Errorf("expected response code %d, got %d", tt.expectedResponseCode, rw.Code)
+			}
+
+			assertHeader(t, rw.Header(), tt.expectedHeader)
+		})
+	}
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	mux := &ServeMux{CORS: &CORSConfig{AllowedOrigins: []string{"*"}, ExposedHeaders: []string{"X-Total-Count"}}}
+	mux.Handle("/posts", http.MethodGet, stringHandler("a"))
+	mux.Handle("/private", http.MethodGet, stringHandler("b"), CORS(&CORSConfig{
+		AllowedOrigins:   []string{"https://example.com"},
+		AllowCredentials: true,
+	}))
+	mux.Handle("/public", http.MethodGet, stringHandler("c"), CORS(&CORSConfig{
+		AllowedOrigins:   []string{"*"},
+		AllowCredentials: true,
+	}))
+
+	cases := []struct {
+		name           string
+		requestURL     string
+		origin         string
+		expectedHeader http.Header
+	}{
+		{
+			name:       "any origin",
+			requestURL: "/posts",
+			origin:     "https://example.com",
+			expectedHeader: http.Header{
+				"Access-Control-Allow-Origin":   {"*"},
+				"Access-Control-Expose-Headers": {"X-Total-Count"},
+				"Content-Type":                  {"text/plain; charset=utf-8"},
+				"Vary":                          {"Origin"},
+			},
+		},
+		{
+			name:       "same origin",
+			requestURL: "/posts",
+			expectedHeader: http.Header{
+				"Content-Type": {"text/plain; charset=utf-8"},
+			},
+		},
+		{
+			name:       "route config",
+			requestURL: "/private",
+			origin:     "https://example.com",
+			expectedHeader: http.Header{
+				"Access-Control-Allow-Origin":      {"https://example.com"},
+				"Access-Control-Allow-Credentials": {"true"},
+				"Content-Type":                     {"text/plain; charset=utf-8"},
+				"Vary":                             {"Origin"},
+			},
+		},
+		{
+			name:       "any origin never allows credentials",
+			requestURL: "/public",
+			origin:     "https://evil.example",
+			expectedHeader: http.Header{
+				"Access-Control-Allow-Origin": {"*"},
+				"Content-Type":                {"text/plain; charset=utf-8"},
+				"Vary":                        {"Origin"},
+			},
+		},
+		{
+			name:       "route config disallowed origin",
+			requestURL: "/private",
+			origin:     "https://other.com",
+			expectedHeader: http.Header{
+				"Content-Type": {"text/plain; charset=utf-8"},
+				"Vary":         {"Origin"},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.requestURL, nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+
+			mux.ServeHTTP(rw, req)
+
+			assertHeader(t, rw.Header(), tt.expectedHeader)
+		})
+	}
+}
+
+func TestCORSPreflightWithoutConfig(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Handle("/posts", http.MethodGet, stringHandler("a"))
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodOptions, "/posts", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	mux.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected response code %d, got %d", http.StatusMethodNotAllowed, rw.Code)
+	}
+}
+
+func assertHeader(t *testing.T, actual, expected http.Header) {
+	t.Helper()
+
+	if len(actual) != len(expected) {
+		t.Errorf("expected header %v, got %v", expected, actual)
+		return
+	}
+
+	for key, values := range expected {
+		if len(actual[key]) != len(values) {
+			t.Errorf("expected header %s to be %v, got %v", key, values, actual[key])
+			continue
+		}
+
+		for i := range values {
+			if actual[key][i] != values[i] {
+				t.Errorf("expected header %s to be %v, got %v", key, values, actual[key])
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package gemux
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ErrorKind describes why a request couldn't be routed.
+type ErrorKind int
+
+const (
+	// NotFound means no route matches the path of the request.
+	NotFound ErrorKind = iota + 1
+
+	// MethodNotAllowed means routes match the path of the request, but none of
+	// them match the method.
+	MethodNotAllowed
+
+	// NotAcceptable means routes match the path and method of the request, but
+	// the request doesn't satisfy the predicates of any of them.
+	NotAcceptable
+
+	// UnsupportedMediaType means routes match the path and method of the
+	// request, but none of them accept the content type of the request.
+	UnsupportedMediaType
+)
+
+// String returns a description of the kind of error, such as "not found".
+func (kind ErrorKind) String() string {
+	switch kind {
+	case Found:
+		return "found"
+	case NotFound:
+		return "not found"
+	case MethodNotAllowed:
+		return "method not allowed"
+	case NotAcceptable:
+		return "not acceptable"
+	case UnsupportedMediaType:
+		return "unsupported media type"
+	default:
+		return "unknown routing error"
+	}
+}
+
+// Status returns the HTTP status code for the kind of error.
+func (kind ErrorKind) Status() int {
+	switch kind {
+	case Found:
+		return http.StatusOK
+	case NotFound:
+		return http.StatusNotFound
+	case MethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case NotAcceptable:
+		return http.StatusNotAcceptable
+	case UnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// defaultHandler returns the handler used for the kind of error when neither a
+// specific handler nor ErrorHandler is set on the mux.
+func (kind ErrorKind) defaultHandler() http.Handler {
+	switch kind {
+	case MethodNotAllowed:
+		return MethodNotAllowedHandler()
+	case NotAcceptable:
+		return NotAcceptableHandler()
+	case UnsupportedMediaType:
+		return UnsupportedMediaTypeHandler()
+	default:
+		return http.NotFoundHandler()
+	}
+}
+
+// RoutingError describes why a request couldn't be routed. It is passed to the
+// ErrorHandler of a ServeMux.
+type RoutingError struct {
+	// Kind is the reason routing failed.
+	Kind ErrorKind
+
+	// Path is the path of the request that was routed, which is escaped if
+	// UseEscapedPath is set on the mux.
+	Path string
+
+	// MatchedPrefix is the longest prefix of the cleaned request path that
+	// matched the routing tree, such as "/posts/5" for a request to
+	// "/posts/5/likes" when only "/posts/*/comments" is registered. If the
+	// whole path matched, MatchedPrefix is the cleaned path.
+	MatchedPrefix string
+
+	// MatchedPattern is the pattern of the routing tree MatchedPrefix matched,
+	// such as "/posts/*".
+	MatchedPattern string
+
+	// AllowedMethods are the sorted methods registered for the path of the
+	// request, if it matched the routing tree. It doesn't include the "*"
+	// method.
+	AllowedMethods []string
+
+	// RequestID is the ID given to the request by the RequestID middleware,
+	// if it is added with Use.
+	RequestID string
+}
+
+// Error returns a description of the routing error.
+func (err *RoutingError) Error() string {
+	return "gemux: " + err.Kind.String() + ": " + err.Path
+}
+
+// Status returns the HTTP status code for the routing error.
+func (err *RoutingError) Status() int {
+	return err.Kind.Status()
+}
+
+// routingError returns a RoutingError of the given kind for a request that
+// matched depth segments of the routing tree, ending at node.
+func (mux *ServeMux) routingError(kind ErrorKind, r *http.Request, node *ServeMux, depth int) *RoutingError {
+	var prefix, pattern strings.Builder
+
+	current := mux
+	path := mux.routingPath(r)
+	head, tail := shiftPath(path)
+	for i := 0; i < depth && head != ""; i++ {
+		prefix.WriteString("/" + head)
+
+		if current.wildcardChild != nil {
+			pattern.WriteString("/*")
+			current = current.wildcardChild
+		} else {
+			child, segment, _ := mux.lookupChild(current, mux.decodeSegment(head))
+			pattern.WriteString("/" + segment)
+			current = child
+		}
+
+		head, tail = shiftPath(tail)
+	}
+
+	err := &RoutingError{
+		Kind:           kind,
+		Path:           path,
+		MatchedPrefix:  prefix.String(),
+		MatchedPattern: pattern.String(),
+	}
+
+	if err.MatchedPrefix == "" {
+		err.MatchedPrefix = "/"
+		err.MatchedPattern = "/"
+	}
+
+	if kind != NotFound {
+		err.AllowedMethods = node.allowedMethods()
+	}
+
+	return err
+}
@@ -0,0 +1,141 @@
+package gemux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestErrorHandler(t *testing.T) {
+	cases := []struct {
+		name                string
+		requestURL          string
+		requestMethod       string
+		requestHeader       http.Header
+		expectedError       RoutingError
+		expectedAllowHeader string
+	}{
+		{
+			name:          "root not found",
+			requestURL:    "/foo",
+			requestMethod: http.MethodGet,
+			expectedError: RoutingError{
+				Kind:           NotFound,
+				Path:           "/foo",
+				MatchedPrefix:  "/",
+				MatchedPattern: "/",
+			},
+		},
+		{
+			name:          "partial match",
+			requestURL:    "/posts/5/likes",
+			requestMethod: http.MethodGet,
+			expectedError: RoutingError{
+				Kind:           NotFound,
+				Path:           "/posts/5/likes",
+				MatchedPrefix:  "/posts/5",
+				MatchedPattern: "/posts/*",
+			},
+		},
+		{
+			name:          "node without handlers",
+			requestURL:    "/posts/5",
+			requestMethod: http.MethodGet,
+			expectedError: RoutingError{
+				Kind:           NotFound,
+				Path:           "/posts/5",
+				MatchedPrefix:  "/posts/5",
+				MatchedPattern: "/posts/*",
+			},
+		},
+		{
+			name:          "method not allowed",
+			requestURL:    "/posts/5/comments",
+			requestMethod: http.MethodDelete,
+			expectedError: RoutingError{
+				Kind:           MethodNotAllowed,
+				Path:           "/posts/5/comments",
+				MatchedPrefix:  "/posts/5/comments",
+				MatchedPattern: "/posts/*/comments",
+				AllowedMethods: []string{http.MethodGet, http.MethodPost},
+			},
+			expectedAllowHeader: "GET, POST",
+		},
+		{
+			name:          "unsupported media type",
+			requestURL:    "/posts/5/comments",
+			requestMethod: http.MethodPost,
+			requestHeader: http.Header{"Content-Type": {"text/plain"}},
+			expectedError: RoutingError{
+				Kind:           UnsupportedMediaType,
+				Path:           "/posts/5/comments",
+				MatchedPrefix:  "/posts/5/comments",
+				MatchedPattern: "/posts/*/comments",
+				AllowedMethods: []string{http.MethodGet, http.MethodPost},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual *RoutingError
+
+			mux := new(ServeMux)
+			mux.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err *RoutingError) {
+				actual = err
+				w.WriteHeader(err.Status())
+			}
+
+			mux.Handle("/posts/*/comments", http.MethodGet, stringHandler("a"))
+			mux.Handle("/posts/*/comments", http.MethodPost, stringHandler("b"), ContentType("application/json"))
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.requestMethod, tt.requestURL, nil)
+			for key, values := range tt.requestHeader {
+				req.Header[key] = values
+			}
+
+			mux.ServeHTTP(rw, req)
+
+			if actual == nil {
+				t.Fatalf("expected error handler to be called")
+			}
+
+			if !reflect.DeepEqual(*actual, tt.expectedError) {
+				t.Errorf("expected routing error %+v, got %+v", tt.expectedError, *actual)
+			}
+
+			if rw.Code != tt.expectedError.Kind.Status() {
+				t.Errorf("expected response code %d, got %d", tt.expectedError.Kind.Status(), rw.Code)
+			}
+
+			if allow := rw.Header().Get("Allow"); allow != tt.expectedAllowHeader {
+				t.Errorf("expected Allow header %q, got %q", tt.expectedAllowHeader, allow)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerPrecedence(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Handle("/foo", http.MethodGet, stringHandler("a"))
+	mux.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err *RoutingError) {
+		http.Error(w, err.Error(), err.Status())
+	}
+	mux.NotFoundHandler = stringHandler("custom not found")
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/bar", nil))
+
+	if body := rw.Body.String(); body != "custom not found" {
+		t.Errorf("expected NotFoundHandler to take precedence, got body %q", body)
+	}
+
+	rw = httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodPut, "/foo", nil))
+
+	if body := rw.Body.String(); body != "gemux: method not allowed: /foo\n" {
+		t.Errorf("expected ErrorHandler to be used, got body %q", body)
+	}
+}
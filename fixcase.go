@@ -0,0 +1,50 @@
+package gemux
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redirectFixedCase redirects the request to its path with each static segment
+// in the case it was registered with. Path parameters and the query are kept as
+// they are.
+func (mux *ServeMux) redirectFixedCase(w http.ResponseWriter, r *http.Request) {
+	var decoded, escaped strings.Builder
+
+	path := mux.routingPath(r)
+	current := mux
+	for head, tail := shiftPath(path); head != ""; head, tail = shiftPath(tail) {
+		segment := mux.decodeSegment(head)
+		escapedSegment := head
+
+		if current.wildcardChild != nil {
+			current = current.wildcardChild
+		} else {
+			current, segment, _ = mux.lookupChild(current, segment)
+			escapedSegment = url.PathEscape(segment)
+		}
+
+		decoded.WriteString("/" + segment)
+		escaped.WriteString("/" + escapedSegment)
+	}
+
+	if strings.HasSuffix(path, "/") {
+		decoded.WriteString("/")
+		escaped.WriteString("/")
+	}
+
+	u := *r.URL
+	u.Path = decoded.String()
+	u.RawPath = ""
+	if mux.UseEscapedPath {
+		u.RawPath = escaped.String()
+	}
+
+	code := http.StatusMovedPermanently
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		code = http.StatusPermanentRedirect
+	}
+
+	http.Redirect(w, r, u.String(), code)
+}
@@ -0,0 +1,119 @@
+package gemux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCaseInsensitivePaths(t *testing.T) {
+	cases := []struct {
+		name                 string
+		redirectFixedCase    bool
+		useEscapedPath       bool
+		requestURL           string
+		requestMethod        string
+		expectedResponseCode int
+		expectedLocation     string
+	}{
+		{
+			name:                 "exact match",
+			requestURL:           "/posts/AbC/comments",
+			requestMethod:        http.MethodGet,
+			expectedResponseCode: http.StatusOK,
+		},
+		{
+			name:                 "serve directly",
+			requestURL:           "/Posts/AbC/COMMENTS",
+			requestMethod:        http.MethodGet,
+			expectedResponseCode: http.StatusOK,
+		},
+		{
+			name:                 "redirect get",
+			redirectFixedCase:    true,
+			requestURL:           "/Posts/AbC/COMMENTS?page=2",
+			requestMethod:        http.MethodGet,
+			expectedResponseCode: http.StatusMovedPermanently,
+			expectedLocation:     "/posts/AbC/comments?page=2",
+		},
+		{
+			name:                 "redirect post",
+			redirectFixedCase:    true,
+			requestURL:           "/POSTS/AbC/comments/",
+			requestMethod:        http.MethodPost,
+			expectedResponseCode: http.StatusPermanentRedirect,
+			expectedLocation:     "/posts/AbC/comments/",
+		},
+		{
+			name:                 "redirect escaped path",
+			redirectFixedCase:    true,
+			useEscapedPath:       true,
+			requestURL:           "/Posts/A%2FbC/comments",
+			requestMethod:        http.MethodGet,
+			expectedResponseCode: http.StatusMovedPermanently,
+			expectedLocation:     "/posts/A%2FbC/comments",
+		},
+		{
+			name:                 "no redirect when method not allowed",
+			redirectFixedCase:    true,
+			requestURL:           "/POSTS/AbC/comments",
+			requestMethod:        http.MethodDelete,
+			expectedResponseCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:                 "no redirect when not acceptable",
+			redirectFixedCase:    true,
+			requestURL:           "/POSTS/AbC/comments",
+			requestMethod:        http.MethodPut,
+			expectedResponseCode: http.StatusNotAcceptable,
+		},
+		{
+			name:                 "no redirect when not found",
+			redirectFixedCase:    true,
+			requestURL:           "/Posts",
+			requestMethod:        http.MethodGet,
+			expectedResponseCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := &ServeMux{
+				CaseInsensitivePaths: true,
+				RedirectFixedCase:    tt.redirectFixedCase,
+				UseEscapedPath:       tt.useEscapedPath,
+			}
+
+			mux.HandleMethods("/posts/*/comments", []string{http.MethodGet, http.MethodPost}, http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					if param := PathParameter(r.Context(), 0); param != "AbC" {
+						t.Errorf("expected path parameter %q, got %q", "AbC", param)
+					}
+				}))
+			mux.Handle("/posts/*/comments", http.MethodPut, stringHandler("v2"), Query("version", "2"))
+
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(tt.requestMethod, tt.requestURL, nil))
+
+			if rw.Code != tt.expectedResponseCode {
+				t.Errorf("expected response code %d, got %d", tt.expectedResponseCode, rw.Code)
+			}
+
+			if location := rw.Header().Get("Location"); location != tt.expectedLocation {
+				t.Errorf("expected Location header %q, got %q", tt.expectedLocation, location)
+			}
+		})
+	}
+}
+
+func TestCaseSensitiveByDefault(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Handle("/posts", http.MethodGet, stringHandler("a"))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/Posts", nil))
+
+	if rw.Code != http.StatusNotFound {
+		t.Errorf("expected response code %d, got %d", http.StatusNotFound, rw.Code)
+	}
+}
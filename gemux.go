@@ -3,105 +3,359 @@ package gemux
 import (
 	"context"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // ServeMux is an HTTP request multiplexer. It matches the URL and method of the incoming
 // request against a list of registered routes, and calls the matching route.
 type ServeMux struct {
-	handlers        map[string]http.Handler // methods describe actions on a resource
-	wildcardHandler http.Handler            // * method
-	children        map[string]*ServeMux    // paths describe resources
-	wildcardChild   *ServeMux               // * path
+	handlers       map[string][]*route  // methods describe actions on a resource
+	wildcardRoutes []*route             // * method
+	children       map[string]*ServeMux // paths describe resources
+	wildcardChild  *ServeMux            // * path
+	pattern        string               // pattern of the node, empty for the root
+	replaced       []*route             // routes replaced by later registrations, kept for Analyze
 
 	// NotFoundHandler is called when there is no path corresponding to
-	// the request URL. If NotFoundHandler is nil, http.NotFoundHandler
-	// will be used.
+	// the request URL. If NotFoundHandler is nil, ErrorHandler will be
+	// used, or http.NotFoundHandler if ErrorHandler is also nil.
 	NotFoundHandler http.Handler
 
 	// MethodNotAllowedHandler is called when there is no method corresponding
-	// to the request URL. If MethodNotAllowedHandler is nil, MethodNotAllowedHandler
-	// will be used.
+	// to the request URL. If MethodNotAllowedHandler is nil, ErrorHandler will be
+	// used, or MethodNotAllowedHandler if ErrorHandler is also nil.
 	MethodNotAllowedHandler http.Handler
+
+	// NotAcceptableHandler is called when the path and method of the request
+	// match, but the request doesn't satisfy the predicates of any route, such
+	// as those added with Header, Query or Scheme. If NotAcceptableHandler is nil,
+	// ErrorHandler will be used, or NotAcceptableHandler if ErrorHandler is also
+	// nil.
+	NotAcceptableHandler http.Handler
+
+	// UnsupportedMediaTypeHandler is called when the path and method of the
+	// request match, but no route matches because of a ContentType predicate.
+	// If UnsupportedMediaTypeHandler is nil, ErrorHandler will be used, or
+	// UnsupportedMediaTypeHandler if ErrorHandler is also nil.
+	UnsupportedMediaTypeHandler http.Handler
+
+	// NormalizeMethods makes method matching case insensitive by converting
+	// the methods of routes and requests to upper case, so a route registered
+	// with "get" matches GET requests. Methods are case sensitive by default.
+	NormalizeMethods bool
+
+	// ErrorHandler is called when a request can't be routed and the handler
+	// for that kind of error, such as NotFoundHandler, is nil. It receives a
+	// RoutingError describing why routing failed. If ErrorHandler is nil, the
+	// default handler for the kind of error is used.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err *RoutingError)
+
+	// UseEscapedPath routes requests on the escaped path of the request URL
+	// instead of the decoded path, so an escaped slash ("%2F") in a path
+	// parameter doesn't split it into two segments. Each segment is decoded on
+	// its own before being matched against patterns or returned by
+	// PathParameter.
+	UseEscapedPath bool
+
+	// CaseInsensitivePaths makes static path segments match case
+	// insensitively when no segment matches exactly, so a request to
+	// "/Posts/5" is routed to "/posts/*". Path parameters keep the case of the
+	// request.
+	CaseInsensitivePaths bool
+
+	// RedirectFixedCase redirects requests that only matched because of
+	// CaseInsensitivePaths to the path with the registered case, instead of
+	// serving them directly. GET and HEAD requests are redirected with a 301,
+	// and other requests with a 308 so the method and body are kept. Only
+	// requests that match a route are redirected, so a request with a method
+	// that isn't allowed is answered with a 405 directly.
+	RedirectFixedCase bool
+
+	// PanicHandler is called with the recovered value when a handler panics.
+	// If PanicHandler is nil, the panic is logged and a 500 response is sent if
+	// the header hasn't been written yet, otherwise the response is aborted.
+	// Panics with http.ErrAbortHandler are never recovered from.
+	PanicHandler func(w http.ResponseWriter, r *http.Request, v interface{})
+
+	// TimeoutHandler is called when the handler of a route registered with the
+	// Timeout option doesn't return in time. If TimeoutHandler is nil,
+	// TimeoutHandler will be used.
+	TimeoutHandler http.Handler
+
+	// MaxBodyBytes limits the size of request bodies for routes that don't
+	// set their own limit with the MaxBodyBytes option. If MaxBodyBytes is
+	// zero or negative, request bodies aren't limited.
+	MaxBodyBytes int64
+
+	// RequestEntityTooLargeHandler is called when a request body is larger
+	// than the limit of its route. If RequestEntityTooLargeHandler is nil,
+	// RequestEntityTooLargeHandler will be used.
+	RequestEntityTooLargeHandler http.Handler
+
+	// TooManyRequestsHandler is called when a request is over the limit of a
+	// RateLimiter of its route. If TooManyRequestsHandler is nil,
+	// TooManyRequestsHandler will be used.
+	TooManyRequestsHandler http.Handler
+
+	// CORS configures cross-origin resource sharing for routes that don't set
+	// their own configuration with the CORS option. If CORS is nil, only those
+	// routes allow cross-origin requests. Preflight requests for paths without
+	// an OPTIONS route are answered by the mux.
+	CORS *CORSConfig
+
+	// Metrics records request counts, error counts and latencies for each
+	// route, and counts of requests that couldn't be routed. If Metrics is
+	// nil, nothing is recorded.
+	Metrics *Metrics
+
+	// Hooks is called at each stage of routing and serving a request, such
+	// as to start a span named after the matched route. If Hooks is nil, no
+	// hooks are called.
+	Hooks Hooks
+
+	middleware []func(http.Handler) http.Handler // added with Use
+	chainOnce  sync.Once
+	chain      http.Handler // middleware wrapping serveChained, built on the first request
 }
 
 // ServeHTTP dispatches the request to the handler whose pattern and method
 // matches the request URL and method.
 func (mux *ServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	current := mux
+	rw := newResponseWriter(w)
+	w = rw.writer
 
-	for head, tail := shiftPath(r.URL.Path); head != ""; head, tail = shiftPath(tail) {
-		if current.wildcardChild != nil {
-			r = r.WithContext(appendPathParameter(r.Context(), head))
-			current = current.wildcardChild
-			continue
+	var m Match
+
+	var start time.Time
+	if mux.Metrics != nil || mux.Hooks != nil {
+		start = time.Now()
+	}
+
+	if mux.Metrics != nil {
+		defer func() {
+			mux.Metrics.record(&m, rw.status, time.Since(start))
+		}()
+	}
+
+	if mux.Hooks != nil {
+		r = r.WithContext(mux.Hooks.Start(r))
+
+		started := r
+		defer func() {
+			mux.Hooks.Finished(started, rw.statusCode(), rw.bytes, time.Since(start))
+		}()
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			mux.handlePanic(rw, r, v)
 		}
+	}()
 
-		child, ok := current.children[head]
-		if !ok {
-			current.notFoundHandler().ServeHTTP(w, r)
-			return
+	m = mux.MatchRequest(r)
+
+	if len(mux.middleware) == 0 {
+		if len(m.Params) > 0 || m.route != nil {
+			r = r.WithContext(context.WithValue(r.Context(), routeKey, newRouteValue(r.Context(), &m)))
 		}
 
-		current = child
+		mux.serveMatch(w, r, &m)
+		return
 	}
 
-	current.serveHandler(w, r)
+	// the match is passed to the middleware chain through the context, on
+	// its own copy so m doesn't escape when there is no middleware.
+	matched := new(Match)
+	*matched = m
+
+	value := newRouteValue(r.Context(), &m)
+	value.match = matched
+	mux.chained().ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeKey, value)))
+
+	m = *matched
 }
 
-// notFoundHandler returns the mux NotFoundHandler if there is one, otherwise
-// http.NotFoundHandler.
-func (mux *ServeMux) notFoundHandler() http.Handler {
-	if mux.NotFoundHandler != nil {
-		return mux.NotFoundHandler
+// serveChained is the innermost handler of the middleware chain, which replies
+// to the request as resolved by the match its context holds.
+func (mux *ServeMux) serveChained(w http.ResponseWriter, r *http.Request) {
+	value := routeValueFrom(r.Context())
+	if value == nil || value.match == nil {
+		// the middleware replaced the context, so the request is matched again.
+		m := mux.MatchRequest(r)
+		mux.serveMatch(w, r.WithContext(context.WithValue(r.Context(), routeKey, newRouteValue(r.Context(), &m))), &m)
+		return
 	}
 
-	return http.NotFoundHandler()
+	mux.serveMatch(w, r, value.match)
 }
 
-// serveHandler serves the request to the proper method handler, or calls the
-// 404 or 405 handler.
-func (mux *ServeMux) serveHandler(w http.ResponseWriter, r *http.Request) {
-	if mux.handlers == nil {
-		mux.notFoundHandler().ServeHTTP(w, r)
+// serveMatch replies to the request as resolved by m, with the handler of the
+// matched route, a redirect to the fixed case path, a preflight response or a
+// routing error.
+func (mux *ServeMux) serveMatch(w http.ResponseWriter, r *http.Request, m *Match) {
+	if m.Kind == NotFound {
+		mux.serveError(w, r, m.Err)
+		return
+	}
+
+	if len(m.node.handlers[m.method]) == 0 && isPreflight(r, m.method) && mux.servePreflight(w, r, m.node) {
+		m.preflight = true
+		return
+	}
+
+	if m.Kind != Found {
+		mux.serveError(w, r, m.Err)
+		return
+	}
+
+	if m.fixedCase && mux.RedirectFixedCase {
+		mux.redirectFixedCase(w, r)
 		return
 	}
 
-	if mux.wildcardHandler != nil {
-		mux.wildcardHandler.ServeHTTP(w, r)
+	if m.route.produces != "" {
+		w.Header().Add("Vary", "Accept")
+	}
+
+	mux.setCORSHeaders(w, r, m.route)
+
+	if mux.Hooks != nil {
+		mux.Hooks.Matched(r, *m)
+	}
+
+	mux.serveRoute(w, r, m.route)
+}
+
+// serveRoute serves the request with the handler of rt, applying the options of
+// the route.
+func (mux *ServeMux) serveRoute(w http.ResponseWriter, r *http.Request, rt *route) {
+	if !mux.allowRequest(w, r, rt) {
 		return
 	}
 
-	handler, ok := mux.handlers[r.Method]
-	if !ok {
-		mux.methodNotAllowedHandler().ServeHTTP(w, r)
+	handler := rt.handler
+
+	if limit := mux.bodyLimit(rt); limit > 0 {
+		next := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mux.serveWithBodyLimit(w, r, next, limit)
+		})
+	}
+
+	if rt.timeout > 0 {
+		mux.serveWithTimeout(w, r, handler, rt.timeout)
 		return
 	}
 
 	handler.ServeHTTP(w, r)
 }
 
-// methodNotAllowedHandler returns the mux MethodNotAllowedHandler if there is one, otherwise
-// MethodNotAllowedHandler.
-func (mux *ServeMux) methodNotAllowedHandler() http.Handler {
-	if mux.MethodNotAllowedHandler != nil {
-		return mux.MethodNotAllowedHandler
+// serveError replies to a request that couldn't be routed with the handler for
+// the kind of error if one is set, otherwise ErrorHandler if it is set,
+// otherwise the default handler for the kind of error. The Allow header is set
+// before replying to a request with a method that isn't allowed, and the
+// NotFound and MethodNotAllowed hooks are called before replying to those kinds
+// of errors.
+func (mux *ServeMux) serveError(w http.ResponseWriter, r *http.Request, err *RoutingError) {
+	err.RequestID = RequestIDFromContext(r.Context())
+
+	if mux.Hooks != nil {
+		switch err.Kind {
+		case NotFound:
+			mux.Hooks.NotFound(r, err)
+		case MethodNotAllowed:
+			mux.Hooks.MethodNotAllowed(r, err)
+		}
+	}
+
+	if err.Kind == MethodNotAllowed {
+		w.Header().Set("Allow", strings.Join(err.AllowedMethods, ", "))
+	}
+
+	var handler http.Handler
+	switch err.Kind {
+	case NotFound:
+		handler = mux.NotFoundHandler
+	case MethodNotAllowed:
+		handler = mux.MethodNotAllowedHandler
+	case NotAcceptable:
+		handler = mux.NotAcceptableHandler
+	case UnsupportedMediaType:
+		handler = mux.UnsupportedMediaTypeHandler
+	}
+
+	if handler != nil {
+		handler.ServeHTTP(w, r)
+		return
+	}
+
+	if mux.ErrorHandler != nil {
+		mux.ErrorHandler(w, r, err)
+		return
 	}
 
-	return MethodNotAllowedHandler()
+	err.Kind.defaultHandler().ServeHTTP(w, r)
 }
 
 // Handle registers a handler for the given pattern and method on the muxer.
 // The pattern should be the exact URL to match, with the exception of wildcards
 // ("*"), which can be used for a single segment of a path (split on "/") to match
 // anything. A wildcard method of "*" can also be used to match any method.
-func (mux *ServeMux) Handle(pattern string, method string, handler http.Handler) {
+// Other methods, including extension methods such as PROPFIND, must be valid
+// RFC 7230 tokens, otherwise Handle panics. Handle also panics if method is a
+// standard method in the wrong case, such as "get", unless NormalizeMethods is
+// set.
+//
+// Options can add predicates on other parts of the request, such as headers or
+// query parameters. When several routes are registered for the same pattern and
+// method, the route with the most predicates that all match the request is
+// chosen. Registering a route with the same pattern, method and predicates as an
+// existing route replaces it.
+func (mux *ServeMux) Handle(pattern string, method string, handler http.Handler, opts ...RouteOption) {
+	mux.HandleMethods(pattern, []string{method}, handler, opts...)
+}
+
+// HandleMethods registers a handler for the given pattern and each of the given
+// methods on the muxer, like calling Handle once for each method. The methods
+// are validated before any of them are registered, so if HandleMethods panics
+// none of the methods are registered.
+func (mux *ServeMux) HandleMethods(pattern string, methods []string, handler http.Handler, opts ...RouteOption) {
+	if len(methods) == 0 {
+		panic("gemux: no methods given for pattern " + strconv.Quote(pattern))
+	}
+
+	normalized := make([]string, len(methods))
+	for i, method := range methods {
+		if mux.NormalizeMethods {
+			method = strings.ToUpper(method)
+		}
+
+		validateMethod(method)
+		normalized[i] = method
+	}
+
+	proto := &route{handler: handler}
+	for _, opt := range opts {
+		opt(proto)
+	}
+
+	if proto.paramNames != nil {
+		if wildcards := countWildcards(pattern); len(proto.paramNames) != wildcards {
+			panic("gemux: " + strconv.Itoa(len(proto.paramNames)) + " parameter names given for pattern " +
+				strconv.Quote(pattern) + " with " + strconv.Itoa(wildcards) + " wildcards")
+		}
+	}
+
 	current := mux
 
 	for head, tail := shiftPath(pattern); head != ""; head, tail = shiftPath(tail) {
 		if head == "*" {
 			if current.wildcardChild == nil {
-				current.wildcardChild = current.newChild()
+				current.wildcardChild = current.newChild("*")
 			}
 
 			current = current.wildcardChild
@@ -113,51 +367,96 @@ func (mux *ServeMux) Handle(pattern string, method string, handler http.Handler)
 		}
 
 		if current.children[head] == nil {
-			current.children[head] = current.newChild()
+			current.children[head] = current.newChild(head)
 		}
 
 		current = current.children[head]
 	}
 
 	if current.handlers == nil {
-		current.handlers = make(map[string]http.Handler)
+		current.handlers = make(map[string][]*route)
 	}
 
-	if method == "*" {
-		current.wildcardHandler = handler
-	} else {
-		current.handlers[method] = handler
+	for _, method := range normalized {
+		rt := new(route)
+		*rt = *proto
+		rt.method = method
+		rt.pattern = pattern
+
+		description := rt.describe(current.nodePattern())
+		rt.description = &description
+
+		var replaced *route
+		if method == "*" {
+			current.wildcardRoutes, replaced = addRoute(current.wildcardRoutes, rt)
+		} else {
+			current.handlers[method], replaced = addRoute(current.handlers[method], rt)
+		}
+
+		if replaced != nil {
+			current.replaced = append(current.replaced, replaced)
+		}
+	}
+}
+
+// newChild returns a pointer to a new ServeMux to be used as the node of the
+// routing tree for segment under mux. Error handlers and other options are
+// always taken from the mux ServeHTTP is called on, so they aren't set on
+// children.
+func (mux *ServeMux) newChild(segment string) *ServeMux {
+	return &ServeMux{pattern: mux.pattern + "/" + segment}
+}
+
+// nodePattern returns the pattern of the routing tree the node is for, which is
+// "/" for the root.
+func (mux *ServeMux) nodePattern() string {
+	if mux.pattern == "" {
+		return "/"
 	}
+
+	return mux.pattern
 }
 
-// newChild returns a pointer to a new ServeMux with NotFoundHandler
-// and MethodNotAllowedHandler set to the parent mux values.
-func (mux *ServeMux) newChild() *ServeMux {
-	return &ServeMux{
-		MethodNotAllowedHandler: mux.MethodNotAllowedHandler,
-		NotFoundHandler:         mux.NotFoundHandler,
+// countWildcards returns the number of wildcard segments in pattern.
+func countWildcards(pattern string) int {
+	n := 0
+	for head, tail := shiftPath(pattern); head != ""; head, tail = shiftPath(tail) {
+		if head == "*" {
+			n++
+		}
 	}
+
+	return n
 }
 
 // PathParameter returns the nth path parameter from the request
 // context. It returns an empty string if no value exists at the
 // given index.
 func PathParameter(ctx context.Context, n int) string {
-	contextValue := ctx.Value(pathParametersKey)
-	if contextValue == nil {
+	value := routeValueFrom(ctx)
+	if value == nil || n < 0 || n >= len(value.params) {
 		return ""
 	}
 
-	pathParameters, ok := contextValue.([]string)
-	if !ok {
+	return value.params[n]
+}
+
+// NamedPathParameter returns the path parameter with the given name from the
+// request context, for routes registered with the ParamNames option. It returns
+// an empty string if no path parameter has the given name.
+func NamedPathParameter(ctx context.Context, name string) string {
+	value := routeValueFrom(ctx)
+	if value == nil || value.route == nil {
 		return ""
 	}
 
-	if n < 0 || n >= len(pathParameters) {
-		return ""
+	for i, paramName := range value.route.ParamNames {
+		if paramName == name {
+			return PathParameter(ctx, i)
+		}
 	}
 
-	return pathParameters[n]
+	return ""
 }
 
 // MethodNotAllowedHandler returns a simple request handler that replies to
@@ -169,6 +468,75 @@ func MethodNotAllowedHandler() http.Handler {
 	})
 }
 
+// NotAcceptableHandler returns a simple request handler that replies to each
+// request with a "406 not acceptable" reply and writes the 406 status code.
+func NotAcceptableHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "406 not acceptable", http.StatusNotAcceptable)
+	})
+}
+
+// UnsupportedMediaTypeHandler returns a simple request handler that replies to
+// each request with a "415 unsupported media type" reply and writes the 415
+// status code.
+func UnsupportedMediaTypeHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "415 unsupported media type", http.StatusUnsupportedMediaType)
+	})
+}
+
+// lookupChild returns the static child of node for segment, along with the
+// segment it was registered with. If CaseInsensitivePaths is set and there is no
+// exact match, the first child in sorted order whose segment matches case
+// insensitively is returned.
+func (mux *ServeMux) lookupChild(node *ServeMux, segment string) (*ServeMux, string, bool) {
+	if child, ok := node.children[segment]; ok {
+		return child, segment, true
+	}
+
+	if !mux.CaseInsensitivePaths {
+		return nil, "", false
+	}
+
+	var fixed string
+	for registered := range node.children {
+		if strings.EqualFold(registered, segment) && (fixed == "" || registered < fixed) {
+			fixed = registered
+		}
+	}
+
+	if fixed == "" {
+		return nil, "", false
+	}
+
+	return node.children[fixed], fixed, true
+}
+
+// routingPath returns the path of the request that is matched against the
+// routing tree.
+func (mux *ServeMux) routingPath(r *http.Request) string {
+	if mux.UseEscapedPath {
+		return r.URL.EscapedPath()
+	}
+
+	return r.URL.Path
+}
+
+// decodeSegment returns the decoded form of a segment of the routing path. If
+// the segment can't be decoded it is returned as is.
+func (mux *ServeMux) decodeSegment(segment string) string {
+	if !mux.UseEscapedPath {
+		return segment
+	}
+
+	decoded, err := url.PathUnescape(segment)
+	if err != nil {
+		return segment
+	}
+
+	return decoded
+}
+
 func shiftPath(p string) (head, tail string) {
 	p = cleanPath("/" + p)
 	i := strings.Index(p[1:], "/") + 1
@@ -181,19 +549,80 @@ func shiftPath(p string) (head, tail string) {
 type contextKey int
 
 const (
-	pathParametersKey contextKey = iota
+	routeKey contextKey = iota
+	requestIDKey
 )
 
-// appendPathParameter pushes a path parameter to the given context.
-func appendPathParameter(ctx context.Context, pathParameter string) context.Context {
-	var pathParameters []string
+// routeValue is the value held by the context of a routed request, with the
+// route it matched and its path parameters.
+type routeValue struct {
+	route  *Route // nil if the request didn't match a route
+	params []string
+	match  *Match // set for the middleware chain, nil otherwise
+}
+
+// routeValueFrom returns the routeValue held by ctx, or nil if it holds none.
+func routeValueFrom(ctx context.Context) *routeValue {
+	value, _ := ctx.Value(routeKey).(*routeValue)
+	return value
+}
+
+// WithPathParameters returns a copy of ctx holding params as the path
+// parameters returned by PathParameter and NamedPathParameter, replacing any
+// path parameters ctx already holds. It is meant for testing handlers without a
+// ServeMux.
+func WithPathParameters(ctx context.Context, params ...string) context.Context {
+	value := &routeValue{params: append([]string{}, params...)}
+	if existing := routeValueFrom(ctx); existing != nil {
+		value.route = existing.route
+	}
+
+	return context.WithValue(ctx, routeKey, value)
+}
+
+// WithRoute returns a copy of ctx holding rt as the route the request matched,
+// as returned by RouteFromContext. The names of the path parameters returned
+// by NamedPathParameter are taken from rt.ParamNames.
+func WithRoute(ctx context.Context, rt Route) context.Context {
+	value := &routeValue{route: &rt}
+	if existing := routeValueFrom(ctx); existing != nil {
+		value.params = existing.params
+	}
+
+	return context.WithValue(ctx, routeKey, value)
+}
+
+// RouteFromContext returns the route the request with the given context
+// matched, if it was routed by a ServeMux.
+func RouteFromContext(ctx context.Context) (Route, bool) {
+	value := routeValueFrom(ctx)
+	if value == nil || value.route == nil {
+		return Route{}, false
+	}
+
+	return *value.route, true
+}
+
+// newRouteValue returns the routeValue for a request matched by m, with the
+// route it matched and its path parameters after any path parameters ctx
+// already holds, such as those matched by a ServeMux the request was routed
+// through before. If m didn't match a route, the route ctx holds is kept.
+func newRouteValue(ctx context.Context, m *Match) *routeValue {
+	value := &routeValue{params: m.Params}
+	if m.route != nil {
+		value.route = m.route.description
+	}
+
+	if existing := routeValueFrom(ctx); existing != nil {
+		if len(existing.params) > 0 {
+			params := make([]string, 0, len(existing.params)+len(m.Params))
+			value.params = append(append(params, existing.params...), m.Params...)
+		}
 
-	if contextValue := ctx.Value(pathParametersKey); contextValue != nil {
-		value, ok := contextValue.([]string)
-		if ok {
-			pathParameters = value
+		if value.route == nil {
+			value.route = existing.route
 		}
 	}
 
-	return context.WithValue(ctx, pathParametersKey, append(pathParameters, pathParameter))
+	return value
 }
@@ -288,6 +288,115 @@ func TestServeMux(t *testing.T) {
 	}
 }
 
+func TestHandleMethods(t *testing.T) {
+	mux := new(ServeMux)
+	mux.HandleMethods("/foo", []string{http.MethodGet, http.MethodHead}, stringHandler("a"))
+	mux.HandleMethods("/foo", []string{http.MethodPut, http.MethodPatch}, stringHandler("b"))
+
+	cases := []struct {
+		method               string
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{http.MethodGet, http.StatusOK, "a"},
+		{http.MethodHead, http.StatusOK, "a"},
+		{http.MethodPut, http.StatusOK, "b"},
+		{http.MethodPatch, http.StatusOK, "b"},
+		{http.MethodPost, http.StatusMethodNotAllowed, "405 method not allowed\n"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.method, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(tt.method, "/foo", nil))
+
+			if rw.Code != tt.expectedResponseCode {
+				t.Errorf("expected response code %d, got %d", tt.expectedResponseCode, rw.Code)
+			}
+
+			if body := rw.Body.String(); body != tt.expectedResponseBody {
+				t.Errorf("expected response body %q, got %q", tt.expectedResponseBody, body)
+			}
+		})
+	}
+}
+
+func TestHandleMethodsAtomic(t *testing.T) {
+	cases := []struct {
+		name    string
+		methods []string
+	}{
+		{"invalid method", []string{http.MethodGet, "BAD METHOD"}},
+		{"wrong case", []string{http.MethodPut, "patch"}},
+		{"no methods", nil},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := new(ServeMux)
+
+			func() {
+				defer func() {
+					if recovered := recover(); recovered == nil {
+						t.Errorf("expected HandleMethods to panic")
+					}
+				}()
+
+				mux.HandleMethods("/foo", tt.methods, stringHandler("a"))
+			}()
+
+			if routes := mux.Routes(); len(routes) != 0 {
+				t.Errorf("expected no routes to be registered, got %v", routes)
+			}
+		})
+	}
+}
+
+func TestUseEscapedPath(t *testing.T) {
+	cases := []struct {
+		name                 string
+		useEscapedPath       bool
+		requestURL           string
+		expectedResponseCode int
+		expectedParams       []string
+	}{
+		{
+			name:                 "encoded slash in parameter",
+			useEscapedPath:       true,
+			requestURL:           "/objects/a%2Fb/meta",
+			expectedResponseCode: http.StatusOK,
+			expectedParams:       []string{"a/b"},
+		},
+		{
+			name:                 "encoded static segment",
+			useEscapedPath:       true,
+			requestURL:           "/obj%65cts/a%20b/meta",
+			expectedResponseCode: http.StatusOK,
+			expectedParams:       []string{"a b"},
+		},
+		{
+			name:                 "decoded path",
+			useEscapedPath:       false,
+			requestURL:           "/objects/a%2Fb/meta",
+			expectedResponseCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := &ServeMux{UseEscapedPath: tt.useEscapedPath}
+			mux.Handle("/objects/*/meta", http.MethodGet, pathParametersHandler(t, "a", tt.expectedParams))
+
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, tt.requestURL, nil))
+
+			if rw.Code != tt.expectedResponseCode {
+				t.Errorf("expected response code %d, got %d", tt.expectedResponseCode, rw.Code)
+			}
+		})
+	}
+}
+
 func TestPathParameter(t *testing.T) {
 	testCases := []struct {
 		name              string
@@ -297,19 +406,19 @@ func TestPathParameter(t *testing.T) {
 	}{
 		{
 			name:              "ordinary",
-			ctx:               context.WithValue(context.Background(), pathParametersKey, []string{"foo", "42"}),
+			ctx:               WithPathParameters(context.Background(), "foo", "42"),
 			n:                 1,
 			expectedParameter: "42",
 		},
 		{
 			name:              "under bounds",
-			ctx:               context.WithValue(context.Background(), pathParametersKey, []string{"foo", "42"}),
+			ctx:               WithPathParameters(context.Background(), "foo", "42"),
 			n:                 -1,
 			expectedParameter: "",
 		},
 		{
 			name:              "over bounds",
-			ctx:               context.WithValue(context.Background(), pathParametersKey, []string{"foo", "42"}),
+			ctx:               WithPathParameters(context.Background(), "foo", "42"),
 			n:                 2,
 			expectedParameter: "",
 		},
@@ -321,7 +430,7 @@ func TestPathParameter(t *testing.T) {
 		},
 		{
 			name:              "wrong type",
-			ctx:               context.WithValue(context.Background(), pathParametersKey, "foo"),
+			ctx:               context.WithValue(context.Background(), routeKey, "foo"),
 			n:                 0,
 			expectedParameter: "",
 		},
@@ -337,6 +446,62 @@ func TestPathParameter(t *testing.T) {
 	}
 }
 
+func TestNamedPathParameter(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Handle("/posts/*/comments/*", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		fmt.Fprintf(w, "%s %s %q", NamedPathParameter(ctx, "postID"), NamedPathParameter(ctx, "commentID"),
+			NamedPathParameter(ctx, "missing"))
+	}), ParamNames("postID", "commentID"))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/posts/4/comments/2", nil))
+
+	if expected := `4 2 ""`; rw.Body.String() != expected {
+		t.Errorf("expected response body %s, got %s", expected, rw.Body.String())
+	}
+
+	if actual := NamedPathParameter(context.Background(), "postID"); actual != "" {
+		t.Errorf("expected no parameter without names, got %s", actual)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic registering mismatched parameter names")
+		}
+	}()
+
+	mux.Handle("/users/*", http.MethodGet, stringHandler("user"), ParamNames("userID", "extra"))
+}
+
+func TestRouteFromContext(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Handle("/posts/*", "*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rt, ok := RouteFromContext(r.Context())
+		if !ok {
+			t.Fatalf("expected route in context")
+		}
+
+		fmt.Fprintf(w, "%s %s %v", rt.Method, rt.Pattern, rt.ParamNames)
+	}), ParamNames("postID"))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/posts/4", nil))
+
+	if expected := "* /posts/* [postID]"; rw.Body.String() != expected {
+		t.Errorf("expected response body %s, got %s", expected, rw.Body.String())
+	}
+
+	if _, ok := RouteFromContext(context.Background()); ok {
+		t.Errorf("expected no route without routing")
+	}
+
+	ctx := WithRoute(WithPathParameters(context.Background(), "4"), Route{ParamNames: []string{"postID"}})
+	if param := NamedPathParameter(ctx, "postID"); param != "4" {
+		t.Errorf("expected named parameter 4, got %s", param)
+	}
+}
+
 func ExampleServeMux() {
 	mux := new(ServeMux)
 
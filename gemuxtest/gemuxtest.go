@@ -0,0 +1,213 @@
+// Package gemuxtest provides utilities for testing handlers and route tables
+// built with gemux.
+package gemuxtest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fharding1/gemux"
+)
+
+// Param is a path parameter, as matched by a wildcard of a pattern. Name may be
+// empty for parameters that are only retrieved with gemux.PathParameter.
+type Param struct {
+	Name  string
+	Value string
+}
+
+// Context returns a copy of ctx holding params as its path parameters, in
+// order, as if a request had been routed by a ServeMux. If any of the params
+// are named, the names are added to the route held by ctx, so they can be
+// retrieved with gemux.NamedPathParameter.
+func Context(ctx context.Context, params ...Param) context.Context {
+	values := make([]string, len(params))
+	names := make([]string, len(params))
+	named := false
+
+	for i, param := range params {
+		values[i] = param.Value
+		names[i] = param.Name
+		named = named || param.Name != ""
+	}
+
+	ctx = gemux.WithPathParameters(ctx, values...)
+	if !named {
+		return ctx
+	}
+
+	rt, _ := gemux.RouteFromContext(ctx)
+	rt.ParamNames = names
+
+	return gemux.WithRoute(ctx, rt)
+}
+
+// NewRequest returns a new incoming server request for testing, like
+// httptest.NewRequest, whose context holds params as its path parameters.
+func NewRequest(method, target string, body io.Reader, params ...Param) *http.Request {
+	r := httptest.NewRequest(method, target, body)
+	return r.WithContext(Context(r.Context(), params...))
+}
+
+// WithRoute returns a shallow copy of r whose context holds rt as the route it
+// matched, as returned by gemux.RouteFromContext. If rt doesn't name its path
+// parameters, the names already held by the context of r are kept.
+func WithRoute(r *http.Request, rt gemux.Route) *http.Request {
+	ctx := r.Context()
+
+	if rt.ParamNames == nil {
+		if existing, ok := gemux.RouteFromContext(ctx); ok {
+			rt.ParamNames = existing.ParamNames
+		}
+	}
+
+	return r.WithContext(gemux.WithRoute(ctx, rt))
+}
+
+// AssertRoute fails the test unless a request with the given method and path
+// is routed by mux to the route registered for pattern, such as "/posts/*",
+// with the given path parameters. The route must be registered for the method
+// of the request, unless pattern starts with another method and a space, such
+// as "* /posts/*" for a route that matches any method. Use AssertHandler to
+// check the handler the request is routed to instead.
+func AssertRoute(t testing.TB, mux *gemux.ServeMux, method, path, pattern string, params ...string) {
+	t.Helper()
+
+	routeMethod := method
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		routeMethod, pattern = pattern[:i], pattern[i+1:]
+	}
+
+	m := mux.Match(method, path)
+	if m.Kind != gemux.Found {
+		t.Errorf("%s %s: expected route %s %s to be found, got %s", method, path, routeMethod, pattern, m.Kind)
+		return
+	}
+
+	if m.Pattern != cleanPattern(pattern) || !sameMethod(mux, m.Route.Method, routeMethod) {
+		t.Errorf("%s %s: expected route %s %s, got %s %s", method, path, routeMethod, pattern, m.Route.Method, m.Pattern)
+	}
+
+	assertParams(t, method, path, m, params)
+}
+
+// AssertHandler fails the test unless a request with the given method and path
+// is routed by mux to handler, with the given path parameters. Handlers are
+// compared with SameHandler, so handlers created by the same function literal,
+// such as closures or gemux.JSON handlers, can't be told apart. Use
+// AssertRoute to tell those apart by the route they are registered for.
+func AssertHandler(t testing.TB, mux *gemux.ServeMux, method, path string, handler http.Handler, params ...string) {
+	t.Helper()
+
+	m := mux.Match(method, path)
+	if m.Kind != gemux.Found {
+		t.Errorf("%s %s: expected handler %T to be found, got %s", method, path, handler, m.Kind)
+		return
+	}
+
+	if !SameHandler(m.Handler, handler) {
+		t.Errorf("%s %s: expected handler %T, got %T matched by %s %s", method, path, handler, m.Handler,
+			m.Route.Method, m.Pattern)
+	}
+
+	assertParams(t, method, path, m, params)
+}
+
+// assertParams fails the test unless m matched the given path parameters.
+func assertParams(t testing.TB, method, path string, m gemux.Match, params []string) {
+	t.Helper()
+
+	if len(params) == 0 && len(m.Params) == 0 {
+		return
+	}
+
+	if !reflect.DeepEqual(m.Params, params) {
+		t.Errorf("%s %s: expected path parameters %q, got %q", method, path, params, m.Params)
+	}
+}
+
+// sameMethod reports whether a route registered with method on mux has the
+// expected method.
+func sameMethod(mux *gemux.ServeMux, method, expected string) bool {
+	if mux.NormalizeMethods {
+		return strings.EqualFold(method, expected)
+	}
+
+	return method == expected
+}
+
+// AssertNotFound fails the test unless a request with the given method and
+// path is answered by mux with a gemux.NotFound routing error.
+func AssertNotFound(t testing.TB, mux *gemux.ServeMux, method, path string) {
+	t.Helper()
+
+	if m := mux.Match(method, path); m.Kind != gemux.NotFound {
+		t.Errorf("%s %s: expected %s, got %s", method, path, gemux.NotFound, describeMatch(m))
+	}
+}
+
+// AssertMethodNotAllowed fails the test unless a request with the given
+// method and path is answered by mux with a gemux.MethodNotAllowed routing
+// error, with the given sorted methods allowed.
+func AssertMethodNotAllowed(t testing.TB, mux *gemux.ServeMux, method, path string, allowed ...string) {
+	t.Helper()
+
+	m := mux.Match(method, path)
+	if m.Kind != gemux.MethodNotAllowed {
+		t.Errorf("%s %s: expected %s, got %s", method, path, gemux.MethodNotAllowed, describeMatch(m))
+		return
+	}
+
+	if strings.Join(m.Err.AllowedMethods, ", ") != strings.Join(allowed, ", ") {
+		t.Errorf("%s %s: expected allowed methods %q, got %q", method, path, allowed, m.Err.AllowedMethods)
+	}
+}
+
+// SameHandler reports whether a and b are the same handler. Handlers that are
+// functions, such as an http.HandlerFunc, or pointers are the same if they
+// point to the same code or value. Other handlers are compared with
+// reflect.DeepEqual.
+//
+// Functions can only be compared by their code, so closures created by the
+// same function literal are always the same handler, even if they capture
+// different values. Two handlers returned by gemux.JSON for the same types are
+// the same handler for example, so compare the responses of handlers like
+// these instead.
+func SameHandler(a, b http.Handler) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+
+	switch va.Kind() {
+	case reflect.Func, reflect.Ptr:
+		return va.Pointer() == vb.Pointer()
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// cleanPattern returns pattern as it is matched by a ServeMux, without empty
+// segments or a trailing slash.
+func cleanPattern(pattern string) string {
+	return path.Clean("/" + pattern)
+}
+
+// describeMatch returns a description of m for failure messages.
+func describeMatch(m gemux.Match) string {
+	if m.Kind == gemux.Found {
+		return "route " + m.Pattern
+	}
+
+	return m.Kind.String()
+}
@@ -0,0 +1,204 @@
+package gemuxtest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fharding1/gemux"
+)
+
+// recorder is a testing.TB that records failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (rec *recorder) Helper() {}
+
+func (rec *recorder) Errorf(format string, args ...interface{}) {
+	rec.failures = append(rec.failures, fmt.Sprintf(format, args...))
+}
+
+func getPost(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintf(w, "%s %s", gemux.PathParameter(r.Context(), 0), gemux.NamedPathParameter(r.Context(), "postID"))
+}
+
+func listPosts(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.WriteString(w, "posts")
+}
+
+// stringHandler returns a handler that writes body, to show that closures of
+// the same function literal can't be told apart.
+func stringHandler(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+func TestNewRequest(t *testing.T) {
+	cases := []struct {
+		name         string
+		params       []Param
+		expectedBody string
+	}{
+		{"named", []Param{{Name: "postID", Value: "5"}}, "5 5"},
+		{"positional", []Param{{Value: "5"}}, "5 "},
+		{"none", nil, " "},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			http.HandlerFunc(getPost).ServeHTTP(rw, NewRequest(http.MethodGet, "/posts/5", nil, tt.params...))
+
+			if body := rw.Body.String(); body != tt.expectedBody {
+				t.Errorf("expected response body %q, got %q", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestWithRoute(t *testing.T) {
+	r := NewRequest(http.MethodGet, "/posts/5", nil, Param{Name: "postID", Value: "5"})
+	r = WithRoute(r, gemux.Route{Pattern: "/posts/*", Method: http.MethodGet})
+
+	rt, ok := gemux.RouteFromContext(r.Context())
+	if !ok || rt.Pattern != "/posts/*" || rt.Method != http.MethodGet {
+		t.Errorf("expected route GET /posts/*, got %v", rt)
+	}
+
+	if param := gemux.NamedPathParameter(r.Context(), "postID"); param != "5" {
+		t.Errorf("expected named parameter to be kept, got %q", param)
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	mux := new(gemux.ServeMux)
+	mux.Handle("/posts", http.MethodGet, http.HandlerFunc(listPosts))
+	mux.Handle("/posts/*", http.MethodGet, http.HandlerFunc(getPost))
+	mux.Handle("/posts/*", http.MethodDelete, http.HandlerFunc(getPost))
+	mux.Handle("/drafts/*", http.MethodGet, http.HandlerFunc(getPost))
+	mux.Handle("/any", "*", http.HandlerFunc(listPosts))
+	mux.Handle("/feed", http.MethodGet, http.HandlerFunc(listPosts))
+	mux.Handle("/feed", http.MethodGet, http.HandlerFunc(getPost), gemux.Query("version", "2"))
+
+	cases := []struct {
+		name             string
+		assert           func(t testing.TB)
+		expectedFailures int
+	}{
+		{"route", func(t testing.TB) {
+			AssertRoute(t, mux, http.MethodGet, "/posts/5", "/posts/*", "5")
+		}, 0},
+		{"route without parameters", func(t testing.TB) {
+			AssertRoute(t, mux, http.MethodGet, "/posts", "/posts")
+		}, 0},
+		{"uncleaned pattern", func(t testing.TB) {
+			AssertRoute(t, mux, http.MethodGet, "/posts/5", "posts/*/", "5")
+		}, 0},
+		{"wrong route", func(t testing.TB) {
+			AssertRoute(t, mux, http.MethodGet, "/posts", "/posts/*")
+		}, 1},
+		{"same handler for another route", func(t testing.TB) {
+			AssertRoute(t, mux, http.MethodGet, "/drafts/5", "/posts/*", "5")
+		}, 1},
+		{"wrong parameters", func(t testing.TB) {
+			AssertRoute(t, mux, http.MethodGet, "/posts/5", "/posts/*", "6")
+		}, 1},
+		{"route not found", func(t testing.TB) {
+			AssertRoute(t, mux, http.MethodGet, "/users", "/users")
+		}, 1},
+		{"route for any method", func(t testing.TB) {
+			AssertRoute(t, mux, http.MethodGet, "/any", "* /any")
+		}, 0},
+		{"route for any method instead of request method", func(t testing.TB) {
+			AssertRoute(t, mux, http.MethodGet, "/any", "/any")
+		}, 1},
+		{"route for request method instead of any method", func(t testing.TB) {
+			AssertRoute(t, mux, http.MethodGet, "/posts", "* /posts")
+		}, 1},
+		{"handler", func(t testing.TB) {
+			AssertHandler(t, mux, http.MethodGet, "/posts/5", http.HandlerFunc(getPost), "5")
+		}, 0},
+		{"wrong handler", func(t testing.TB) {
+			AssertHandler(t, mux, http.MethodGet, "/posts", http.HandlerFunc(getPost))
+		}, 1},
+		{"handler of predicate variant", func(t testing.TB) {
+			AssertHandler(t, mux, http.MethodGet, "/feed?version=2", http.HandlerFunc(getPost))
+		}, 0},
+		{"handler without predicate", func(t testing.TB) {
+			AssertHandler(t, mux, http.MethodGet, "/feed", http.HandlerFunc(getPost))
+		}, 1},
+		{"handler with wrong parameters", func(t testing.TB) {
+			AssertHandler(t, mux, http.MethodGet, "/posts/5", http.HandlerFunc(getPost), "6")
+		}, 1},
+		{"handler not found", func(t testing.TB) {
+			AssertHandler(t, mux, http.MethodGet, "/users", http.HandlerFunc(getPost))
+		}, 1},
+		{"not found", func(t testing.TB) {
+			AssertNotFound(t, mux, http.MethodGet, "/users")
+		}, 0},
+		{"found instead of not found", func(t testing.TB) {
+			AssertNotFound(t, mux, http.MethodGet, "/posts")
+		}, 1},
+		{"method not allowed", func(t testing.TB) {
+			AssertMethodNotAllowed(t, mux, http.MethodPut, "/posts/5", http.MethodDelete, http.MethodGet)
+		}, 0},
+		{"wrong allowed methods", func(t testing.TB) {
+			AssertMethodNotAllowed(t, mux, http.MethodPut, "/posts/5", http.MethodGet)
+		}, 1},
+		{"found instead of method not allowed", func(t testing.TB) {
+			AssertMethodNotAllowed(t, mux, http.MethodGet, "/posts/5")
+		}, 1},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{TB: t}
+			tt.assert(rec)
+
+			if len(rec.failures) != tt.expectedFailures {
+				t.Errorf("expected %d failures, got %q", tt.expectedFailures, rec.failures)
+			}
+		})
+	}
+}
+
+func TestSameHandler(t *testing.T) {
+	notFound := http.NotFoundHandler()
+	fileServer := http.FileServer(http.Dir("."))
+
+	// closures are created at the same call site, so inlining can't give them
+	// different code.
+	closures := make([]http.Handler, 2)
+	for i, body := range []string{"a", "b"} {
+		closures[i] = stringHandler(body)
+	}
+
+	cases := []struct {
+		name     string
+		a, b     http.Handler
+		expected bool
+	}{
+		{"same function", http.HandlerFunc(getPost), http.HandlerFunc(getPost), true},
+		{"different functions", http.HandlerFunc(getPost), http.HandlerFunc(listPosts), false},
+		{"same closure", notFound, notFound, true},
+		{"closures of the same function literal", closures[0], closures[1], true},
+		{"same pointer", fileServer, fileServer, true},
+		{"different pointers", fileServer, http.FileServer(http.Dir(".")), false},
+		{"different types", notFound, fileServer, false},
+		{"nil", nil, nil, true},
+		{"one nil", notFound, nil, false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := SameHandler(tt.a, tt.b); actual != tt.expected {
+				t.Errorf("expected %t, got %t", tt.expected, actual)
+			}
+		})
+	}
+}
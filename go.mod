@@ -1,3 +1,3 @@
 module github.com/fharding1/gemux
 
-go 1.12
+go 1.21
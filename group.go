@@ -0,0 +1,60 @@
+package gemux
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Group registers routes on a ServeMux under a common pattern prefix, with a
+// common set of route options.
+type Group struct {
+	mux    *ServeMux
+	prefix string
+	opts   []RouteOption
+}
+
+// Group returns a Group that registers routes on the mux under prefix, such as
+// "/api/v1", with opts applied to each route before the route's own options.
+func (mux *ServeMux) Group(prefix string, opts ...RouteOption) *Group {
+	return &Group{mux: mux, prefix: prefix, opts: opts}
+}
+
+// Group returns a Group nested under the group, with the prefix appended to
+// the group's prefix and opts applied after the group's options.
+func (g *Group) Group(prefix string, opts ...RouteOption) *Group {
+	return &Group{mux: g.mux, prefix: joinPattern(g.prefix, prefix), opts: g.withOptions(opts)}
+}
+
+// Handle registers a handler for the pattern under the group's prefix and the
+// given method, like ServeMux.Handle.
+func (g *Group) Handle(pattern string, method string, handler http.Handler, opts ...RouteOption) {
+	g.mux.Handle(joinPattern(g.prefix, pattern), method, handler, g.withOptions(opts)...)
+}
+
+// HandleMethods registers a handler for the pattern under the group's prefix and
+// each of the given methods, like ServeMux.HandleMethods.
+func (g *Group) HandleMethods(pattern string, methods []string, handler http.Handler, opts ...RouteOption) {
+	g.mux.HandleMethods(joinPattern(g.prefix, pattern), methods, handler, g.withOptions(opts)...)
+}
+
+// withOptions returns the group's options followed by opts.
+func (g *Group) withOptions(opts []RouteOption) []RouteOption {
+	combined := make([]RouteOption, 0, len(g.opts)+len(opts))
+	combined = append(combined, g.opts...)
+	return append(combined, opts...)
+}
+
+// joinPattern returns pattern appended to prefix, with a single slash between
+// them. A pattern of "" or "/" refers to the prefix itself.
+func joinPattern(prefix, pattern string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if pattern == "" || pattern == "/" {
+		if prefix == "" {
+			return "/"
+		}
+
+		return prefix
+	}
+
+	return prefix + "/" + strings.TrimPrefix(pattern, "/")
+}
@@ -0,0 +1,67 @@
+package gemux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGroup(t *testing.T) {
+	mux := new(ServeMux)
+
+	api := mux.Group("/api/", Header("X-Api-Key", "secret"))
+	api.Handle("/", http.MethodGet, stringHandler("index"))
+	api.Handle("posts", http.MethodGet, stringHandler("posts"))
+
+	v2 := api.Group("/v2", Timeout(time.Second))
+	v2.HandleMethods("/posts/*", []string{http.MethodGet, http.MethodHead}, stringHandler("post"))
+
+	cases := []struct {
+		requestURL           string
+		apiKey               string
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{"/api", "secret", http.StatusOK, "index"},
+		{"/api/posts", "secret", http.StatusOK, "posts"},
+		{"/api/v2/posts/1", "secret", http.StatusOK, "post"},
+		{"/api/v2/posts/1", "", http.StatusNotAcceptable, "406 not acceptable\n"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.requestURL, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.requestURL, nil)
+			req.Header.Set("X-Api-Key", tt.apiKey)
+
+			mux.ServeHTTP(rw, req)
+
+			if rw.Code != tt.expectedResponseCode {
+				t.Errorf("expected response code %d, got %d", tt.expectedResponseCode, rw.Code)
+			}
+
+			if body := rw.Body.String(); body != tt.expectedResponseBody {
+				t.Errorf("expected response body %q, got %q", tt.expectedResponseBody, body)
+			}
+		})
+	}
+}
+
+func TestJoinPattern(t *testing.T) {
+	cases := []struct {
+		prefix, pattern, expected string
+	}{
+		{"", "", "/"},
+		{"/", "/", "/"},
+		{"/api", "/", "/api"},
+		{"/api/", "posts", "/api/posts"},
+		{"/api", "/posts/", "/api/posts/"},
+	}
+
+	for _, tt := range cases {
+		if actual := joinPattern(tt.prefix, tt.pattern); actual != tt.expected {
+			t.Errorf("joinPattern(%q, %q) = %q, want %q", tt.prefix, tt.pattern, actual, tt.expected)
+		}
+	}
+}
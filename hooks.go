@@ -0,0 +1,55 @@
+package gemux
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Hooks is called by a ServeMux at each stage of routing and serving a
+// request, so tracers and other instrumentation can be attached without gemux
+// depending on them. Embed NopHooks to only implement some of the methods.
+type Hooks interface {
+	// Start is called when the mux starts serving a request, before it is
+	// routed. The returned context replaces the context of the request, so a
+	// span started here is seen by the handler and the other hooks.
+	Start(r *http.Request) context.Context
+
+	// Matched is called when the request matched a route, before its handler
+	// is called. The match holds the pattern of the route and the path
+	// parameters.
+	Matched(r *http.Request, m Match)
+
+	// NotFound is called when no route matches the path of the request,
+	// before the request is replied to.
+	NotFound(r *http.Request, err *RoutingError)
+
+	// MethodNotAllowed is called when routes match the path of the request,
+	// but none of them match the method, before the request is replied to.
+	MethodNotAllowed(r *http.Request, err *RoutingError)
+
+	// Finished is called once the request has been replied to, with the
+	// status code, the number of bytes written to the response body and the
+	// time taken since Start. It is also called if the handler panicked,
+	// after the panic has been handled.
+	Finished(r *http.Request, status int, bytes int64, d time.Duration)
+}
+
+// NopHooks implements Hooks with methods that do nothing. Embed it in a type
+// that only needs some of the hooks.
+type NopHooks struct{}
+
+// Start returns the context of the request.
+func (NopHooks) Start(r *http.Request) context.Context { return r.Context() }
+
+// Matched does nothing.
+func (NopHooks) Matched(r *http.Request, m Match) {}
+
+// NotFound does nothing.
+func (NopHooks) NotFound(r *http.Request, err *RoutingError) {}
+
+// MethodNotAllowed does nothing.
+func (NopHooks) MethodNotAllowed(r *http.Request, err *RoutingError) {}
+
+// Finished does nothing.
+func (NopHooks) Finished(r *http.Request, status int, bytes int64, d time.Duration) {}
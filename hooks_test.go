@@ -0,0 +1,139 @@
+package gemux
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type spanKey struct{}
+
+// recordingHooks records the hooks called, as strings.
+type recordingHooks struct {
+	events []string
+}
+
+func (h *recordingHooks) Start(r *http.Request) context.Context {
+	h.events = append(h.events, "start "+r.Method+" "+r.URL.Path)
+	return context.WithValue(r.Context(), spanKey{}, "span")
+}
+
+func (h *recordingHooks) Matched(r *http.Request, m Match) {
+	h.events = append(h.events, fmt.Sprintf("matched %s %v %v", m.Pattern, m.Params, r.Context().Value(spanKey{})))
+}
+
+func (h *recordingHooks) NotFound(r *http.Request, err *RoutingError) {
+	h.events = append(h.events, "not found "+err.MatchedPattern)
+}
+
+func (h *recordingHooks) MethodNotAllowed(r *http.Request, err *RoutingError) {
+	h.events = append(h.events, "method not allowed "+strings.Join(err.AllowedMethods, ","))
+}
+
+func (h *recordingHooks) Finished(r *http.Request, status int, bytes int64, d time.Duration) {
+	if d <= 0 {
+		h.events = append(h.events, "finished without duration")
+	}
+
+	h.events = append(h.events, fmt.Sprintf("finished %d %d %v", status, bytes, r.Context().Value(spanKey{})))
+}
+
+func TestHooks(t *testing.T) {
+	cases := []struct {
+		name           string
+		requestURL     string
+		requestMethod  string
+		expectedEvents []string
+	}{
+		{
+			name:          "matched",
+			requestURL:    "/posts/5",
+			requestMethod: http.MethodGet,
+			expectedEvents: []string{
+				"start GET /posts/5",
+				"matched /posts/* [5] span",
+				"finished 200 11 span",
+			},
+		},
+		{
+			name:          "no body",
+			requestURL:    "/posts",
+			requestMethod: http.MethodPost,
+			expectedEvents: []string{
+				"start POST /posts",
+				"matched /posts [] span",
+				"finished 200 0 span",
+			},
+		},
+		{
+			name:          "not found",
+			requestURL:    "/posts/5/likes",
+			requestMethod: http.MethodGet,
+			expectedEvents: []string{
+				"start GET /posts/5/likes",
+				"not found /posts/*",
+				"finished 404 19 span",
+			},
+		},
+		{
+			name:          "method not allowed",
+			requestURL:    "/posts/5",
+			requestMethod: http.MethodPut,
+			expectedEvents: []string{
+				"start PUT /posts/5",
+				"method not allowed GET",
+				"finished 405 23 span",
+			},
+		},
+		{
+			name:          "panic",
+			requestURL:    "/panic",
+			requestMethod: http.MethodGet,
+			expectedEvents: []string{
+				"start GET /panic",
+				"matched /panic [] span",
+				"finished 500 26 span",
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			hooks := new(recordingHooks)
+
+			mux := &ServeMux{Hooks: hooks}
+			mux.Handle("/posts", http.MethodPost, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			mux.Handle("/posts/*", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, "%s post %s", r.Context().Value(spanKey{}), PathParameter(r.Context(), 0))
+			}))
+			mux.Handle("/panic", http.MethodGet, panicHandler("oops"))
+
+			var logged bytes.Buffer
+			mux.ServeHTTP(httptest.NewRecorder(), newLoggedRequest(tt.requestMethod, tt.requestURL, &logged))
+
+			if !reflect.DeepEqual(hooks.events, tt.expectedEvents) {
+				t.Errorf("expected events %q, got %q", tt.expectedEvents, hooks.events)
+			}
+		})
+	}
+}
+
+func TestNopHooks(t *testing.T) {
+	type startHooks struct{ NopHooks }
+
+	mux := &ServeMux{Hooks: startHooks{}}
+	mux.Handle("/", http.MethodGet, stringHandler("root"))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if body := rw.Body.String(); body != "root" {
+		t.Errorf("expected response body root, got %s", body)
+	}
+}
@@ -0,0 +1,152 @@
+package gemux
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// StatusCoder is implemented by errors that should be replied to with a
+// specific status code by JSON handlers, and by responses that should be
+// replied with a status code other than 200.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Validator is implemented by requests that validate themselves after being
+// decoded by JSON handlers.
+type Validator interface {
+	Validate() error
+}
+
+// StatusError is an error that JSON handlers reply to with Status.
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+// Error returns the message of the wrapped error.
+func (err *StatusError) Error() string {
+	return err.Err.Error()
+}
+
+// StatusCode returns the status code to reply with.
+func (err *StatusError) StatusCode() int {
+	return err.Status
+}
+
+// Unwrap returns the wrapped error.
+func (err *StatusError) Unwrap() error {
+	return err.Err
+}
+
+// JSON returns a handler that decodes the JSON body of each request into a
+// Req, calls fn with it and encodes the Resp it returns as the JSON response.
+// Requests without a body are passed to fn as the zero Req, which is nil if Req
+// is a pointer. If Req implements Validator, it is validated before fn is
+// called, unless it is a nil pointer.
+//
+// Errors are replied to with a JSON object with an "error" message. Bodies that
+// can't be decoded or fail validation are replied to with a 400, and bodies
+// over a limit set with MaxBodyBytes with a 413. Errors returned by fn or
+// Validate that implement StatusCoder, or wrap an error that does, are replied
+// to with their status code and message. Other errors are replied to with a
+// 500 and a generic message, so internal details aren't leaked. If Resp
+// implements StatusCoder, successful responses are written with its status
+// code instead of 200.
+func JSON[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+
+		if r.Body != nil && r.Body != http.NoBody {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					writeJSONError(w, http.StatusRequestEntityTooLarge, "request body too large")
+					return
+				}
+
+				writeJSONError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+				return
+			}
+		}
+
+		if err := validate(&req); err != nil {
+			writeJSONErrorFor(w, err, http.StatusBadRequest)
+			return
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			writeJSONErrorFor(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		status := http.StatusOK
+		if coder, ok := interface{}(resp).(StatusCoder); ok {
+			status = coder.StatusCode()
+		}
+
+		writeJSON(w, status, resp)
+	})
+}
+
+// validate validates the request if it implements Validator, with either a
+// value or a pointer receiver. Nil pointer requests, such as those of requests
+// without a body, aren't validated.
+func validate[Req any](req *Req) error {
+	if rv := reflect.ValueOf(*req); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+
+	if validator, ok := interface{}(*req).(Validator); ok {
+		return validator.Validate()
+	}
+
+	if validator, ok := interface{}(req).(Validator); ok {
+		return validator.Validate()
+	}
+
+	return nil
+}
+
+// writeJSONErrorFor replies with err, using the status code of the first
+// StatusCoder in its chain, or status otherwise. The messages of 5xx errors
+// without a status code aren't written.
+func writeJSONErrorFor(w http.ResponseWriter, err error, status int) {
+	var coder StatusCoder
+	if errors.As(err, &coder) {
+		writeJSONError(w, coder.StatusCode(), err.Error())
+		return
+	}
+
+	if status >= 500 {
+		writeJSONError(w, status, http.StatusText(status))
+		return
+	}
+
+	writeJSONError(w, status, err.Error())
+}
+
+// writeJSONError replies with a JSON object with the error message.
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{message})
+}
+
+// writeJSON replies with v encoded as JSON.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body = []byte(`{"error":"` + http.StatusText(status) + `"}`)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(body, '\n'))
+}
@@ -0,0 +1,161 @@
+package gemux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type createPostRequest struct {
+	Title string `json:"title"`
+}
+
+func (req createPostRequest) Validate() error {
+	if req.Title == "" {
+		return errors.New("title is required")
+	}
+
+	return nil
+}
+
+type postResponse struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type createdResponse struct {
+	postResponse
+}
+
+func (createdResponse) StatusCode() int {
+	return http.StatusCreated
+}
+
+var errPostNotFound = &StatusError{Status: http.StatusNotFound, Err: errors.New("post not found")}
+
+func TestJSON(t *testing.T) {
+	mux := &ServeMux{MaxBodyBytes: 32}
+	mux.Handle("/posts", http.MethodPost, JSON(func(ctx context.Context, req createPostRequest) (createdResponse, error) {
+		if req.Title == "fail" {
+			return createdResponse{}, errors.New("database password is hunter2")
+		}
+
+		return createdResponse{postResponse{ID: "1", Title: req.Title}}, nil
+	}))
+	mux.Handle("/posts/*", http.MethodGet, JSON(func(ctx context.Context, req struct{}) (postResponse, error) {
+		id := PathParameter(ctx, 0)
+		if id != "1" {
+			return postResponse{}, fmt.Errorf("getting post %s: %w", id, errPostNotFound)
+		}
+
+		return postResponse{ID: id, Title: "hello"}, nil
+	}))
+
+	cases := []struct {
+		name           string
+		requestMethod  string
+		requestURL     string
+		requestBody    string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"created", http.MethodPost, "/posts", `{"title": "hello"}`, http.StatusCreated, `{"id":"1","title":"hello"}`},
+		{"no body", http.MethodGet, "/posts/1", "", http.StatusOK, `{"id":"1","title":"hello"}`},
+		{"invalid JSON", http.MethodPost, "/posts", `{"title": `, http.StatusBadRequest,
+			`{"error":"invalid JSON body: unexpected EOF"}`},
+		{"validation", http.MethodPost, "/posts", `{}`, http.StatusBadRequest, `{"error":"title is required"}`},
+		{"empty body validation", http.MethodPost, "/posts", "", http.StatusBadRequest, `{"error":"title is required"}`},
+		{"too large", http.MethodPost, "/posts", `{"title": "` + strings.Repeat("a", 64) + `"}`,
+			http.StatusRequestEntityTooLarge, `{"error":"request body too large"}`},
+		{"status error", http.MethodGet, "/posts/2", "", http.StatusNotFound, `{"error":"getting post 2: post not found"}`},
+		{"internal error", http.MethodPost, "/posts", `{"title": "fail"}`, http.StatusInternalServerError,
+			`{"error":"Internal Server Error"}`},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.requestMethod, tt.requestURL, strings.NewReader(tt.requestBody))
+			if tt.requestBody == "" {
+				req.Body = http.NoBody
+			}
+
+			req.ContentLength = -1
+			mux.ServeHTTP(rw, req)
+
+			if rw.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rw.Code)
+			}
+
+			if contentType := rw.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("expected JSON content type, got %s", contentType)
+			}
+
+			if body := rw.Body.String(); body != tt.expectedBody+"\n" {
+				t.Errorf("expected response body %s, got %s", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+type pointerValidated struct {
+	Name string `json:"name"`
+}
+
+func (req *pointerValidated) Validate() error {
+	if req.Name == "" {
+		return &StatusError{Status: http.StatusUnprocessableEntity, Err: errors.New("name is required")}
+	}
+
+	return nil
+}
+
+func TestJSONPointerValidator(t *testing.T) {
+	handler := JSON(func(ctx context.Context, req pointerValidated) (string, error) {
+		return req.Name, nil
+	})
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+
+	if rw.Code != http.StatusUnprocessableEntity || rw.Body.String() != `{"error":"name is required"}`+"\n" {
+		t.Errorf("expected 422 validation error, got %d %s", rw.Code, rw.Body.String())
+	}
+}
+
+func TestJSONPointerRequest(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Handle("/", http.MethodPost, JSON(func(ctx context.Context, req *pointerValidated) (string, error) {
+		if req == nil {
+			return "no body", nil
+		}
+
+		return req.Name, nil
+	}))
+
+	cases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"empty body", "", http.StatusOK, `"no body"`},
+		{"valid body", `{"name":"a"}`, http.StatusOK, `"a"`},
+		{"invalid body", `{}`, http.StatusUnprocessableEntity, `{"error":"name is required"}`},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+
+			if rw.Code != tt.expectedStatus || rw.Body.String() != tt.expectedBody+"\n" {
+				t.Errorf("expected %d %s, got %d %s", tt.expectedStatus, tt.expectedBody, rw.Code, rw.Body.String())
+			}
+		})
+	}
+}
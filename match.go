@@ -0,0 +1,133 @@
+package gemux
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Found is the Kind of a Match that resolved to a route. It is the zero
+// ErrorKind, so it never describes a RoutingError.
+const Found ErrorKind = 0
+
+// Match describes the route a ServeMux resolves a request to, without serving
+// it.
+type Match struct {
+	// Kind is Found if a route matched the request, otherwise the reason no
+	// route matched, such as NotFound or MethodNotAllowed.
+	Kind ErrorKind
+
+	// Handler is the handler of the matched route, or nil if no route matched.
+	Handler http.Handler
+
+	// Route describes the matched route, or is nil if no route matched. It is
+	// shared by every match of the route, so it must not be modified.
+	Route *Route
+
+	// Pattern is the pattern of the routing tree the path matched, such as
+	// "/posts/*/comments". It is empty if Kind is NotFound.
+	Pattern string
+
+	// Params are the path parameters matched by the wildcards of Pattern, in
+	// order.
+	Params []string
+
+	// ParamNames are the names given to Params with the ParamNames option, or
+	// nil if the matched route doesn't name them.
+	ParamNames []string
+
+	// Err describes why no route matched, or is nil if Kind is Found.
+	Err *RoutingError
+
+	method    string
+	node      *ServeMux
+	route     *route
+	fixedCase bool
+	preflight bool // set once the mux answered the request as a preflight
+}
+
+// Match returns the route the mux resolves a request with the given method and
+// path to, like ServeHTTP would. The path may include a query, which is matched
+// against Query predicates. Predicates on other parts of the request, such as
+// Header, are matched against a request without them.
+func (mux *ServeMux) Match(method, path string) Match {
+	u, err := url.Parse(path)
+	if err != nil {
+		u = &url.URL{Path: path}
+	}
+
+	return mux.MatchRequest(&http.Request{
+		Method: method,
+		URL:    u,
+		Header: make(http.Header),
+		Host:   u.Host,
+	})
+}
+
+// MatchRequest returns the route the mux resolves the request to, like
+// ServeHTTP would, matching every predicate against the request. It is what
+// ServeHTTP is built on.
+func (mux *ServeMux) MatchRequest(r *http.Request) Match {
+	m := Match{method: r.Method}
+	if mux.NormalizeMethods {
+		m.method = strings.ToUpper(m.method)
+	}
+
+	current := mux
+	depth := 0
+
+	for head, tail := shiftPath(mux.routingPath(r)); head != ""; head, tail = shiftPath(tail) {
+		segment := mux.decodeSegment(head)
+
+		if current.wildcardChild != nil {
+			m.Params = append(m.Params, segment)
+			current = current.wildcardChild
+			depth++
+			continue
+		}
+
+		child, registered, ok := mux.lookupChild(current, segment)
+		if !ok {
+			return m.fail(mux.routingError(NotFound, r, current, depth))
+		}
+
+		m.fixedCase = m.fixedCase || registered != segment
+		current = child
+		depth++
+	}
+
+	if current.handlers == nil {
+		return m.fail(mux.routingError(NotFound, r, current, depth))
+	}
+
+	m.node = current
+	m.Pattern = current.nodePattern()
+
+	methodRoutes := current.handlers[m.method]
+	if len(current.wildcardRoutes) == 0 && len(methodRoutes) == 0 {
+		return m.fail(mux.routingError(MethodNotAllowed, r, current, depth))
+	}
+
+	rt, status := selectRoute(r, current.wildcardRoutes, methodRoutes)
+	if rt == nil {
+		if status == http.StatusUnsupportedMediaType {
+			return m.fail(mux.routingError(UnsupportedMediaType, r, current, depth))
+		}
+
+		return m.fail(mux.routingError(NotAcceptable, r, current, depth))
+	}
+
+	m.route = rt
+	m.Handler = rt.handler
+	m.Route = rt.description
+	m.ParamNames = rt.paramNames
+
+	return m
+}
+
+// fail returns the match with Kind and Err set from err.
+func (m Match) fail(err *RoutingError) Match {
+	m.Kind = err.Kind
+	m.Err = err
+	return m
+}
@@ -0,0 +1,120 @@
+package gemux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	mux := &ServeMux{CaseInsensitivePaths: true}
+	mux.Handle("/", http.MethodGet, stringHandler("root"))
+	mux.Handle("/posts", http.MethodGet, stringHandler("get posts"))
+	mux.Handle("/posts", http.MethodPost, stringHandler("create post"), ContentType("application/json"))
+	mux.Handle("/posts/*", "*", stringHandler("any post"))
+	mux.Handle("/posts/*/comments/*", http.MethodGet, stringHandler("get comment"), ParamNames("postID", "commentID"))
+	mux.Handle("/search", http.MethodGet, stringHandler("search"), Query("q", "gemux"))
+
+	cases := []struct {
+		name               string
+		method             string
+		path               string
+		expectedKind       ErrorKind
+		expectedBody       string
+		expectedPattern    string
+		expectedParams     []string
+		expectedParamNames []string
+	}{
+		{"root", http.MethodGet, "/", Found, "root", "/", nil, nil},
+		{"static", http.MethodGet, "/posts", Found, "get posts", "/posts", nil, nil},
+		{"wildcard method", http.MethodDelete, "/posts/5", Found, "any post", "/posts/*", []string{"5"}, nil},
+		{"named parameters", http.MethodGet, "/posts/5/comments/2", Found, "get comment", "/posts/*/comments/*",
+			[]string{"5", "2"}, []string{"postID", "commentID"}},
+		{"case insensitive", http.MethodGet, "/POSTS", Found, "get posts", "/posts", nil, nil},
+		{"query", http.MethodGet, "/search?q=gemux", Found, "search", "/search", nil, nil},
+		{"not found", http.MethodGet, "/users", NotFound, "", "", nil, nil},
+		{"not found with parameters", http.MethodGet, "/posts/5/likes", NotFound, "", "", []string{"5"}, nil},
+		{"inner node", http.MethodGet, "/posts/5/comments", NotFound, "", "", []string{"5"}, nil},
+		{"method not allowed", http.MethodPut, "/posts", MethodNotAllowed, "", "/posts", nil, nil},
+		{"not acceptable", http.MethodGet, "/search", NotAcceptable, "", "/search", nil, nil},
+		{"unsupported media type", http.MethodPost, "/posts", UnsupportedMediaType, "", "/posts", nil, nil},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mux.Match(tt.method, tt.path)
+
+			if m.Kind != tt.expectedKind {
+				t.Errorf("expected kind %s, got %s", tt.expectedKind, m.Kind)
+			}
+
+			if m.Pattern != tt.expectedPattern {
+				t.Errorf("expected pattern %q, got %q", tt.expectedPattern, m.Pattern)
+			}
+
+			if !reflect.DeepEqual(m.Params, tt.expectedParams) {
+				t.Errorf("expected params %v, got %v", tt.expectedParams, m.Params)
+			}
+
+			if !reflect.DeepEqual(m.ParamNames, tt.expectedParamNames) {
+				t.Errorf("expected param names %v, got %v", tt.expectedParamNames, m.ParamNames)
+			}
+
+			if tt.expectedKind != Found {
+				if m.Handler != nil || m.Route != nil {
+					t.Errorf("expected no handler or route, got %v and %v", m.Handler, m.Route)
+				}
+
+				if m.Err == nil || m.Err.Kind != tt.expectedKind {
+					t.Errorf("expected error of kind %s, got %v", tt.expectedKind, m.Err)
+				}
+
+				return
+			}
+
+			if m.Err != nil {
+				t.Errorf("expected no error, got %v", m.Err)
+			}
+
+			if body := handlerBody(m.Handler); body != tt.expectedBody {
+				t.Errorf("expected handler writing %q, got %q", tt.expectedBody, body)
+			}
+
+			if m.Route == nil || m.Route.Pattern != tt.expectedPattern || handlerBody(m.Route.Handler) != tt.expectedBody {
+				t.Errorf("expected route for pattern %q, got %+v", tt.expectedPattern, m.Route)
+			}
+		})
+	}
+}
+
+func TestMatchRequest(t *testing.T) {
+	mux := &ServeMux{NormalizeMethods: true}
+	mux.Handle("/posts", http.MethodGet, stringHandler("json posts"), Header("Accept", "application/json"))
+	mux.Handle("/posts", http.MethodGet, stringHandler("posts"))
+
+	req, _ := http.NewRequest("get", "/posts", nil)
+	req.Header.Set("Accept", "application/json")
+
+	m := mux.MatchRequest(req)
+	if body := handlerBody(m.Handler); m.Kind != Found || body != "json posts" {
+		t.Errorf("expected json posts handler to be found, got %s %q", m.Kind, body)
+	}
+
+	if body := handlerBody(mux.Match("get", "/posts").Handler); body != "posts" {
+		t.Errorf("expected posts handler without header, got %q", body)
+	}
+
+	m = mux.Match(http.MethodDelete, "/posts")
+	if m.Kind != MethodNotAllowed || !reflect.DeepEqual(m.Err.AllowedMethods, []string{http.MethodGet}) {
+		t.Errorf("expected method not allowed with GET allowed, got %s %v", m.Kind, m.Err)
+	}
+}
+
+// handlerBody returns the response body written by handler for a GET request
+// to "/".
+func handlerBody(handler http.Handler) string {
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rw.Body.String()
+}
@@ -0,0 +1,77 @@
+package gemux
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// standardMethods are the methods defined by RFC 7231 and RFC 5789, used to
+// catch registrations of methods with the wrong case.
+var standardMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// validateMethod panics if method isn't a valid RFC 7230 token, or if it is a
+// standard method in the wrong case, since methods are case sensitive and it
+// would never match a request.
+func validateMethod(method string) {
+	if !isToken(method) {
+		panic("gemux: invalid method " + strconv.Quote(method))
+	}
+
+	for _, standard := range standardMethods {
+		if method != standard && strings.EqualFold(method, standard) {
+			panic("gemux: method " + strconv.Quote(method) + " will never match " + standard + " requests, use " +
+				standard + " or set NormalizeMethods")
+		}
+	}
+}
+
+// isToken reports whether s is a token as defined by RFC 7230 section 3.2.6.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !isTokenChar(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isTokenChar reports whether c is a tchar as defined by RFC 7230 section 3.2.6.
+func isTokenChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
+
+// allowedMethods returns the sorted methods registered on the mux, for use in
+// an Allow header.
+func (mux *ServeMux) allowedMethods() []string {
+	methods := make([]string, 0, len(mux.handlers))
+	for method, routes := range mux.handlers {
+		if len(routes) > 0 {
+			methods = append(methods, method)
+		}
+	}
+
+	sort.Strings(methods)
+	return methods
+}
@@ -0,0 +1,93 @@
+package gemux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateMethod(t *testing.T) {
+	cases := []struct {
+		method      string
+		expectPanic bool
+	}{
+		{method: http.MethodGet},
+		{method: "PROPFIND"},
+		{method: "MKCOL"},
+		{method: "*"},
+		{method: "X-CUSTOM_1.0"},
+		{method: "", expectPanic: true},
+		{method: "GET POST", expectPanic: true},
+		{method: "GET\n", expectPanic: true},
+		{method: "(GET)", expectPanic: true},
+		{method: "get", expectPanic: true},
+		{method: "Delete", expectPanic: true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.method, func(t *testing.T) {
+			defer func() {
+				if recovered := recover(); (recovered != nil) != tt.expectPanic {
+					t.Errorf("expected panic %v, got %v", tt.expectPanic, recovered)
+				}
+			}()
+
+			new(ServeMux).Handle("/", tt.method, stringHandler("a"))
+		})
+	}
+}
+
+func TestNormalizeMethods(t *testing.T) {
+	mux := &ServeMux{NormalizeMethods: true}
+	mux.Handle("/foo", "get", stringHandler("get"))
+	mux.Handle("/foo", "propfind", stringHandler("propfind"))
+
+	for _, method := range []string{"GET", "get", "PropFind"} {
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, httptest.NewRequest(method, "/foo", nil))
+
+		if rw.Code != http.StatusOK {
+			t.Errorf("expected %s to get response code %d, got %d", method, http.StatusOK, rw.Code)
+		}
+	}
+}
+
+func TestAllowHeader(t *testing.T) {
+	cases := []struct {
+		name                 string
+		methodNotAllowed     http.Handler
+		expectedResponseCode int
+	}{
+		{
+			name:                 "default handler",
+			expectedResponseCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name: "custom handler",
+			methodNotAllowed: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			}),
+			expectedResponseCode: http.StatusTeapot,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := &ServeMux{MethodNotAllowedHandler: tt.methodNotAllowed}
+			mux.Handle("/foo", http.MethodGet, stringHandler("a"))
+			mux.Handle("/foo", "PROPFIND", stringHandler("b"))
+			mux.Handle("/foo", "MKCOL", stringHandler("c"))
+
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(http.MethodPut, "/foo", nil))
+
+			if rw.Code != tt.expectedResponseCode {
+				t.Errorf("expected response code %d, got %d", tt.expectedResponseCode, rw.Code)
+			}
+
+			if allow := rw.Header().Get("Allow"); allow != "GET, MKCOL, PROPFIND" {
+				t.Errorf("expected Allow header %q, got %q", "GET, MKCOL, PROPFIND", allow)
+			}
+		})
+	}
+}
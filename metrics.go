@@ -0,0 +1,238 @@
+package gemux
+
+import (
+	"bytes"
+	"expvar"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds in seconds of the buckets of latency
+// histograms.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics records request counts, error counts and latency histograms for
+// the routes of a ServeMux it is set on, along with counts of requests that
+// couldn't be routed. Routes are identified by their method and pattern, such
+// as "GET /posts/*", with "*" as the method of routes that match any method.
+// Metrics is an expvar.Var, so it can be published with expvar. Use NewMetrics
+// to create one.
+type Metrics struct {
+	// Requests counts the requests served by each route, as *expvar.Int
+	// values keyed by method and pattern.
+	Requests *expvar.Map
+
+	// Errors counts the requests served by each route with a 5xx status code,
+	// as *expvar.Int values keyed by method and pattern.
+	Errors *expvar.Map
+
+	// Latency holds a histogram of the time taken to serve the requests of
+	// each route, keyed by method and pattern. Each histogram is written as a
+	// JSON object with the count and sum in seconds of the observed latencies
+	// and the cumulative count of each bucket, keyed by its upper bound.
+	Latency *expvar.Map
+
+	// RoutingErrors counts the requests that couldn't be routed, as
+	// *expvar.Int values keyed by the ErrorKind, such as "not found" or "method
+	// not allowed".
+	RoutingErrors *expvar.Map
+
+	mu sync.Mutex // guards adding histograms to Latency
+}
+
+// NewMetrics returns new Metrics. If name isn't empty, the metrics are
+// published with expvar under name, which panics if name is already in use.
+func NewMetrics(name string) *Metrics {
+	m := &Metrics{
+		Requests:      new(expvar.Map).Init(),
+		Errors:        new(expvar.Map).Init(),
+		Latency:       new(expvar.Map).Init(),
+		RoutingErrors: new(expvar.Map).Init(),
+	}
+
+	if name != "" {
+		expvar.Publish(name, m)
+	}
+
+	return m
+}
+
+// String returns the metrics as a JSON object, implementing expvar.Var.
+func (m *Metrics) String() string {
+	return `{"requests": ` + m.Requests.String() +
+		`, "errors": ` + m.Errors.String() +
+		`, "latency": ` + m.Latency.String() +
+		`, "routing_errors": ` + m.RoutingErrors.String() + `}`
+}
+
+// record records a request that was answered with status after taking d. If
+// the request matched a route it is recorded for that route, otherwise it is
+// counted as a routing error of its kind. Preflight requests answered by the
+// mux are recorded for the OPTIONS method of the matched pattern.
+func (m *Metrics) record(match *Match, status int, d time.Duration) {
+	var key string
+	switch {
+	case match.route != nil:
+		key = match.route.method + " " + match.Pattern
+	case match.preflight:
+		key = http.MethodOptions + " " + match.Pattern
+	default:
+		m.RoutingErrors.Add(match.Kind.String(), 1)
+		return
+	}
+
+	m.Requests.Add(key, 1)
+	if status >= 500 {
+		m.Errors.Add(key, 1)
+	}
+
+	m.histogram(key).observe(d.Seconds())
+}
+
+// histogram returns the latency histogram for key, adding it to Latency if it
+// doesn't exist yet.
+func (m *Metrics) histogram(key string) *histogram {
+	if h, ok := m.Latency.Get(key).(*histogram); ok {
+		return h
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.Latency.Get(key).(*histogram)
+	if !ok {
+		h = newHistogram()
+		m.Latency.Set(key, h)
+	}
+
+	return h
+}
+
+// PrometheusHandler returns a handler that writes the metrics in the
+// Prometheus text exposition format, as the gemux_requests_total,
+// gemux_request_errors_total, gemux_request_duration_seconds and
+// gemux_routing_errors_total metrics.
+func (m *Metrics) PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+
+		buf.WriteString("# HELP gemux_requests_total Requests served by each route.\n")
+		buf.WriteString("# TYPE gemux_requests_total counter\n")
+		m.Requests.Do(func(kv expvar.KeyValue) {
+			buf.WriteString("gemux_requests_total{" + routeLabels(kv.Key) + "} " + kv.Value.String() + "\n")
+		})
+
+		buf.WriteString("# HELP gemux_request_errors_total Requests served by each route with a 5xx status code.\n")
+		buf.WriteString("# TYPE gemux_request_errors_total counter\n")
+		m.Errors.Do(func(kv expvar.KeyValue) {
+			buf.WriteString("gemux_request_errors_total{" + routeLabels(kv.Key) + "} " + kv.Value.String() + "\n")
+		})
+
+		buf.WriteString("# HELP gemux_request_duration_seconds Time taken to serve the requests of each route.\n")
+		buf.WriteString("# TYPE gemux_request_duration_seconds histogram\n")
+		m.Latency.Do(func(kv expvar.KeyValue) {
+			h, ok := kv.Value.(*histogram)
+			if !ok {
+				return
+			}
+
+			labels := routeLabels(kv.Key)
+			counts, count, sum := h.snapshot()
+
+			for i, bound := range latencyBuckets {
+				buf.WriteString("gemux_request_duration_seconds_bucket{" + labels + `,le="` + formatFloat(bound) + `"} ` +
+					strconv.FormatUint(counts[i], 10) + "\n")
+			}
+
+			buf.WriteString("gemux_request_duration_seconds_bucket{" + labels + `,le="+Inf"} ` +
+				strconv.FormatUint(count, 10) + "\n")
+			buf.WriteString("gemux_request_duration_seconds_sum{" + labels + "} " + formatFloat(sum) + "\n")
+			buf.WriteString("gemux_request_duration_seconds_count{" + labels + "} " + strconv.FormatUint(count, 10) + "\n")
+		})
+
+		buf.WriteString("# HELP gemux_routing_errors_total Requests that couldn't be routed.\n")
+		buf.WriteString("# TYPE gemux_routing_errors_total counter\n")
+		m.RoutingErrors.Do(func(kv expvar.KeyValue) {
+			buf.WriteString(`gemux_routing_errors_total{kind="` + escapeLabel(kv.Key) + `"} ` + kv.Value.String() + "\n")
+		})
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+// routeLabels returns the Prometheus labels for a route key such as
+// "GET /posts/*".
+func routeLabels(key string) string {
+	method, pattern := key, ""
+	if i := strings.IndexByte(key, ' '); i >= 0 {
+		method, pattern = key[:i], key[i+1:]
+	}
+
+	return `method="` + escapeLabel(method) + `",pattern="` + escapeLabel(pattern) + `"`
+}
+
+// escapeLabel escapes a Prometheus label value.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatFloat formats f in the shortest representation that parses back to f.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// histogram is a latency histogram with the buckets of latencyBuckets. It is
+// safe for concurrent use.
+type histogram struct {
+	mu     sync.Mutex
+	counts []uint64 // cumulative count of each bucket
+	count  uint64
+	sum    float64
+}
+
+// newHistogram returns an empty histogram.
+func newHistogram() *histogram {
+	return &histogram{counts: make([]uint64, len(latencyBuckets))}
+}
+
+// observe records a latency in seconds.
+func (h *histogram) observe(seconds float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+
+	h.count++
+	h.sum += seconds
+}
+
+// snapshot returns a copy of the cumulative bucket counts, and the count and
+// sum of the observed latencies.
+func (h *histogram) snapshot() ([]uint64, uint64, float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]uint64{}, h.counts...), h.count, h.sum
+}
+
+// String returns the histogram as a JSON object, implementing expvar.Var.
+func (h *histogram) String() string {
+	counts, count, sum := h.snapshot()
+
+	var b strings.Builder
+	b.WriteString(`{"count": ` + strconv.FormatUint(count, 10) + `, "sum": ` + formatFloat(sum) + `, "buckets": {`)
+	for i, bound := range latencyBuckets {
+		b.WriteString(strconv.Quote(formatFloat(bound)) + ": " + strconv.FormatUint(counts[i], 10) + ", ")
+	}
+
+	b.WriteString(`"+Inf": ` + strconv.FormatUint(count, 10) + "}}")
+	return b.String()
+}
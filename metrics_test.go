@@ -0,0 +1,137 @@
+package gemux
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics("")
+
+	mux := &ServeMux{Metrics: metrics, CORS: &CORSConfig{AllowedOrigins: []string{"*"}}}
+	mux.Handle("/posts", http.MethodGet, stringHandler("posts"))
+	mux.Handle("/posts/*", "*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	mux.Handle("/panic", http.MethodGet, panicHandler("oops"))
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/posts"},
+		{http.MethodGet, "/posts"},
+		{http.MethodDelete, "/posts/5"},
+		{http.MethodGet, "/panic"},
+		{http.MethodGet, "/users"},
+		{http.MethodGet, "/users/5"},
+		{http.MethodPost, "/posts"},
+	}
+
+	var logged bytes.Buffer
+	for _, req := range requests {
+		mux.ServeHTTP(httptest.NewRecorder(), newLoggedRequest(req.method, req.path, &logged))
+	}
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/posts", nil)
+	preflight.Header.Set("Origin", "https://example.com")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	mux.ServeHTTP(httptest.NewRecorder(), preflight)
+
+	expectedCounts := []struct {
+		vars     *expvar.Map
+		key      string
+		expected string
+	}{
+		{metrics.Requests, "GET /posts", "2"},
+		{metrics.Requests, "* /posts/*", "1"},
+		{metrics.Requests, "GET /panic", "1"},
+		{metrics.Requests, "OPTIONS /posts", "1"},
+		{metrics.Errors, "* /posts/*", "1"},
+		{metrics.Errors, "GET /panic", "1"},
+		{metrics.RoutingErrors, "not found", "2"},
+		{metrics.RoutingErrors, "method not allowed", "1"},
+	}
+
+	for _, tt := range expectedCounts {
+		if actual := tt.vars.Get(tt.key); actual == nil || actual.String() != tt.expected {
+			t.Errorf("expected %s to be %s, got %v", tt.key, tt.expected, actual)
+		}
+	}
+
+	if actual := metrics.Errors.Get("GET /posts"); actual != nil {
+		t.Errorf("expected no errors for GET /posts, got %s", actual)
+	}
+
+	var histogram struct {
+		Count   int            `json:"count"`
+		Sum     float64        `json:"sum"`
+		Buckets map[string]int `json:"buckets"`
+	}
+
+	if err := json.Unmarshal([]byte(metrics.Latency.Get("GET /posts").String()), &histogram); err != nil {
+		t.Fatalf("expected histogram to be JSON: %v", err)
+	}
+
+	if histogram.Count != 2 || histogram.Buckets["+Inf"] != 2 || histogram.Buckets["10"] != 2 {
+		t.Errorf("expected histogram with 2 observations, got %+v", histogram)
+	}
+
+	var all map[string]interface{}
+	if err := json.Unmarshal([]byte(metrics.String()), &all); err != nil {
+		t.Errorf("expected metrics to be JSON: %v", err)
+	}
+}
+
+func TestMetricsPrometheusHandler(t *testing.T) {
+	metrics := NewMetrics("")
+
+	mux := &ServeMux{Metrics: metrics}
+	mux.Handle("/posts/*", http.MethodGet, stringHandler("post"))
+	mux.Handle("/metrics", http.MethodGet, metrics.PrometheusHandler())
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts/5", nil))
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if contentType := rw.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("expected Prometheus text content type, got %s", contentType)
+	}
+
+	expectedLines := []string{
+		"# TYPE gemux_requests_total counter",
+		`gemux_requests_total{method="GET",pattern="/posts/*"} 1`,
+		"# TYPE gemux_request_duration_seconds histogram",
+		`gemux_request_duration_seconds_bucket{method="GET",pattern="/posts/*",le="10"} 1`,
+		`gemux_request_duration_seconds_bucket{method="GET",pattern="/posts/*",le="+Inf"} 1`,
+		`gemux_request_duration_seconds_count{method="GET",pattern="/posts/*"} 1`,
+		`gemux_routing_errors_total{kind="not found"} 1`,
+	}
+
+	lines := strings.Split(rw.Body.String(), "\n")
+	for _, expected := range expectedLines {
+		found := false
+		for _, line := range lines {
+			found = found || line == expected
+		}
+
+		if !found {
+			t.Errorf("expected line %q in:\n%s", expected, rw.Body.String())
+		}
+	}
+}
+
+func TestNewMetricsPublishes(t *testing.T) {
+	metrics := NewMetrics("gemux_test_metrics")
+
+	if published := expvar.Get("gemux_test_metrics"); published != metrics {
+		t.Errorf("expected metrics to be published, got %v", published)
+	}
+}
@@ -0,0 +1,35 @@
+package gemux
+
+import "net/http"
+
+// Use adds middleware that wraps each request the mux serves after it is
+// routed, including requests answered with a routing error, a redirect or a
+// preflight response. The middleware can read the matched route with
+// RouteFromContext and the path parameters with PathParameter. Middleware is
+// called in the order it was added, so the first middleware is the outermost.
+//
+// Each middleware function is called once, when the mux serves its first
+// request, so state it sets up before returning its handler is shared by every
+// request. Use panics if the mux has already served a request.
+func (mux *ServeMux) Use(middleware ...func(http.Handler) http.Handler) {
+	if mux.chain != nil {
+		panic("gemux: Use called after the mux served a request")
+	}
+
+	mux.middleware = append(mux.middleware, middleware...)
+}
+
+// chained returns the middleware chain of the mux, building it the first time
+// it is called.
+func (mux *ServeMux) chained() http.Handler {
+	mux.chainOnce.Do(func() {
+		var handler http.Handler = http.HandlerFunc(mux.serveChained)
+		for i := len(mux.middleware) - 1; i >= 0; i-- {
+			handler = mux.middleware[i](handler)
+		}
+
+		mux.chain = handler
+	})
+
+	return mux.chain
+}
@@ -0,0 +1,103 @@
+package gemux
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// routeMiddleware returns middleware that writes name and the pattern of the
+// matched route before calling the next handler.
+func routeMiddleware(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rt, _ := RouteFromContext(r.Context())
+			_, _ = io.WriteString(w, name+"("+rt.Pattern+") ")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestUse(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Use(routeMiddleware("a"), routeMiddleware("b"))
+	mux.Use(routeMiddleware("c"))
+	mux.Handle("/posts/*", http.MethodGet, pathParametersHandler(t, "post", []string{"5"}))
+
+	cases := []struct {
+		name                 string
+		requestURL           string
+		requestMethod        string
+		expectedResponseBody string
+	}{
+		{"matched", "/posts/5", http.MethodGet, "a(/posts/*) b(/posts/*) c(/posts/*) post"},
+		{"not found", "/users", http.MethodGet, "a() b() c() 404 page not found\n"},
+		{"method not allowed", "/posts/5", http.MethodPut, "a() b() c() 405 method not allowed\n"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(tt.requestMethod, tt.requestURL, nil))
+
+			if body := rw.Body.String(); body != tt.expectedResponseBody {
+				t.Errorf("expected response body %q, got %q", tt.expectedResponseBody, body)
+			}
+		})
+	}
+}
+
+func TestUseBuildsChainOnce(t *testing.T) {
+	calls := 0
+	counted := 0
+
+	mux := new(ServeMux)
+	mux.Use(func(next http.Handler) http.Handler {
+		calls++
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			counted++
+			next.ServeHTTP(w, r)
+		})
+	})
+	mux.Handle("/posts", http.MethodGet, stringHandler("posts"))
+
+	for i := 0; i < 3; i++ {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts", nil))
+	}
+
+	if calls != 1 {
+		t.Errorf("expected middleware to be called once, got %d", calls)
+	}
+
+	if counted != 3 {
+		t.Errorf("expected middleware state to be shared by 3 requests, got %d", counted)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected Use to panic after the mux served a request")
+		}
+	}()
+
+	mux.Use(routeMiddleware("a"))
+}
+
+func TestUseWithReplacedContext(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.Background()))
+		})
+	})
+	mux.Handle("/posts/*", http.MethodGet, pathParametersHandler(t, "post", []string{"5"}))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/posts/5", nil))
+
+	if body := rw.Body.String(); body != "post" {
+		t.Errorf("expected response body %q, got %q", "post", body)
+	}
+}
@@ -0,0 +1,120 @@
+package gemux
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Produces returns a RouteOption that marks the route as producing responses of
+// the given media type, such as "application/json". Several routes for the same
+// pattern and method can produce different media types, and the mux chooses
+// between them using the q-values of the Accept header of the request. Requests
+// without an Accept header are served by the route registered first. If none of
+// the media types are acceptable, the mux replies with NotAcceptableHandler.
+func Produces(mediaType string) RouteOption {
+	mediaType = strings.ToLower(mediaType)
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+
+	return func(rt *route) {
+		rt.produces = mediaType
+		rt.predicates = append(rt.predicates, predicate{
+			key:    "produces:" + mediaType,
+			status: http.StatusNotAcceptable,
+			match: func(r *http.Request) bool {
+				q, _ := acceptQuality(r.Header, mediaType)
+				return q > 0
+			},
+		})
+	}
+}
+
+// acceptRange is a single media range of an Accept header, such as "text/*;q=0.5".
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// precision returns how precisely the media range describes a media type, so
+// that "text/html" takes precedence over "text/*", which takes precedence over
+// "*/*".
+func (ar acceptRange) precision() int {
+	switch {
+	case ar.mediaType == "*/*":
+		return 1
+	case strings.HasSuffix(ar.mediaType, "/*"):
+		return 2
+	default:
+		return 3
+	}
+}
+
+// parseAccept parses the media ranges of the Accept header. Malformed media
+// ranges are skipped.
+func parseAccept(header http.Header) []acceptRange {
+	var ranges []acceptRange
+
+	for _, line := range header["Accept"] {
+		for _, element := range strings.Split(line, ",") {
+			element = strings.TrimSpace(element)
+			if element == "" {
+				continue
+			}
+
+			mediaType, params, err := mime.ParseMediaType(element)
+			if err != nil {
+				continue
+			}
+
+			q := 1.0
+			if rawQ, ok := params["q"]; ok {
+				q, err = strconv.ParseFloat(rawQ, 64)
+				if err != nil || q < 0 || q > 1 {
+					continue
+				}
+			}
+
+			ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+		}
+	}
+
+	return ranges
+}
+
+// acceptQuality returns the q-value the Accept header gives to mediaType, along
+// with the precision of the media range it came from. The most precise media
+// range that matches is used. A missing Accept header accepts any media type.
+func acceptQuality(header http.Header, mediaType string) (float64, int) {
+	if len(header["Accept"]) == 0 {
+		return 1, 0
+	}
+
+	q, precision := 0.0, 0
+	for _, ar := range parseAccept(header) {
+		if !mediaTypeMatches(ar.mediaType, mediaType) {
+			continue
+		}
+
+		if p := ar.precision(); p > precision {
+			q, precision = ar.q, p
+		}
+	}
+
+	return q, precision
+}
+
+// negotiatesBetter reports whether the request prefers the media type a over b,
+// either because it has a higher q-value or because it was named more precisely.
+func negotiatesBetter(r *http.Request, a, b string) bool {
+	qa, pa := acceptQuality(r.Header, a)
+	qb, pb := acceptQuality(r.Header, b)
+
+	if qa != qb {
+		return qa > qb
+	}
+
+	return pa > pb
+}
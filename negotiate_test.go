@@ -0,0 +1,121 @@
+package gemux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProduces(t *testing.T) {
+	cases := []struct {
+		name                 string
+		accept               []string
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "no accept header",
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "json",
+		},
+		{
+			name:                 "exact",
+			accept:               []string{"application/xml"},
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "xml",
+		},
+		{
+			name:                 "q-values",
+			accept:               []string{"application/json;q=0.5, application/xml;q=0.8"},
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "xml",
+		},
+		{
+			name:                 "precise range beats wildcard",
+			accept:               []string{"*/*, text/csv"},
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "csv",
+		},
+		{
+			name:                 "precise range overrides wildcard q-value",
+			accept:               []string{"application/*, application/json;q=0.1"},
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "xml",
+		},
+		{
+			name:                 "multiple header lines",
+			accept:               []string{"text/html", "text/csv"},
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "csv",
+		},
+		{
+			name:                 "zero q-value",
+			accept:               []string{"text/csv;q=0"},
+			expectedResponseCode: http.StatusNotAcceptable,
+			expectedResponseBody: "406 not acceptable\n",
+		},
+		{
+			name:                 "not acceptable",
+			accept:               []string{"image/png"},
+			expectedResponseCode: http.StatusNotAcceptable,
+			expectedResponseBody: "406 not acceptable\n",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := new(ServeMux)
+			mux.Handle("/foo", http.MethodGet, stringHandler("json"), Produces("application/json"))
+			mux.Handle("/foo", http.MethodGet, stringHandler("xml"), Produces("application/xml"))
+			mux.Handle("/foo", http.MethodGet, stringHandler("csv"), Produces("text/csv"))
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+			req.Header["Accept"] = tt.accept
+
+			mux.ServeHTTP(rw, req)
+
+			if rw.Code != tt.expectedResponseCode {
+				t.Errorf("expected response code %d, got %d", tt.expectedResponseCode, rw.Code)
+			}
+
+			if body := rw.Body.String(); body != tt.expectedResponseBody {
+				t.Errorf("expected response body %q, got %q", tt.expectedResponseBody, body)
+			}
+		})
+	}
+}
+
+func TestProducesVary(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Handle("/foo", http.MethodGet, stringHandler("json"), Produces("application/json"))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/foo", nil))
+
+	if vary := rw.Header().Get("Vary"); vary != "Accept" {
+		t.Errorf("expected Vary header %q, got %q", "Accept", vary)
+	}
+}
+
+func TestProducesCustomNotAcceptable(t *testing.T) {
+	mux := new(ServeMux)
+	mux.NotAcceptableHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotAcceptable)
+		_, _ = w.Write([]byte(`{"error":"not acceptable"}`))
+	})
+	mux.Handle("/foo", http.MethodGet, stringHandler("json"), Produces("application/json"))
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+	req.Header.Set("Accept", "text/html")
+	mux.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusNotAcceptable {
+		t.Errorf("expected response code %d, got %d", http.StatusNotAcceptable, rw.Code)
+	}
+
+	if body := rw.Body.String(); body != `{"error":"not acceptable"}` {
+		t.Errorf("expected custom response body, got %q", body)
+	}
+}
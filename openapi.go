@@ -0,0 +1,500 @@
+package gemux
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operation describes a route for the OpenAPI document generated by
+// ServeMux.OpenAPI.
+type Operation struct {
+	// OperationID uniquely identifies the operation.
+	OperationID string
+
+	// Summary is a short summary of what the operation does.
+	Summary string
+
+	// Description is a longer description of the operation.
+	Description string
+
+	// Tags group operations in documentation tools.
+	Tags []string
+
+	// Parameters describes the parameters of the operation. Path parameters
+	// describe the wildcards of the pattern in order, and name them in the
+	// generated path template.
+	Parameters []Parameter
+
+	// RequestBody is a value whose type describes the JSON request body, such
+	// as CreatePostRequest{}. If RequestBody is nil, the operation has no
+	// request body.
+	RequestBody interface{}
+
+	// Responses describes the responses of the operation by status code. If
+	// Responses is empty, a default response is documented.
+	Responses map[int]Response
+}
+
+// Parameter describes a parameter of an Operation.
+type Parameter struct {
+	// Name is the name of the parameter.
+	Name string
+
+	// In is the location of the parameter, either "path", "query" or "header".
+	In string
+
+	// Description describes the parameter.
+	Description string
+
+	// Required marks the parameter as required. Path parameters are always
+	// required.
+	Required bool
+
+	// Schema is a value whose type describes the parameter, such as int64(0).
+	// If Schema is nil, the parameter is documented as a string.
+	Schema interface{}
+}
+
+// Response describes a response of an Operation.
+type Response struct {
+	// Description describes the response. If Description is empty, the status
+	// text of the status code is used.
+	Description string
+
+	// Body is a value whose type describes the response body, such as
+	// []Post{}. If Body is nil, the response has no body.
+	Body interface{}
+}
+
+// OpenAPIInfo is the metadata of an OpenAPI document.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Describe returns a RouteOption that attaches operation metadata to the route,
+// for use in the document generated by ServeMux.OpenAPI.
+func Describe(op Operation) RouteOption {
+	return func(rt *route) {
+		rt.operation = &op
+	}
+}
+
+// openAPIMethods are the methods OpenAPI 3 can describe. Routes registered for
+// other methods, including the "*" method, are left out of generated documents.
+var openAPIMethods = map[string]string{
+	http.MethodGet:     "get",
+	http.MethodPut:     "put",
+	http.MethodPost:    "post",
+	http.MethodDelete:  "delete",
+	http.MethodOptions: "options",
+	http.MethodHead:    "head",
+	http.MethodPatch:   "patch",
+	http.MethodTrace:   "trace",
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components *openAPIComponents                      `json:"components,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*jsonSchema `json:"schemas,omitempty"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Schema      *jsonSchema `json:"schema,omitempty"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *jsonSchema `json:"schema,omitempty"`
+}
+
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+}
+
+// OpenAPI returns an OpenAPI 3 JSON document describing the routes registered
+// on the mux. Wildcard segments of patterns are turned into path parameters,
+// named by the ParamNames of the route or the path parameters of its Operation,
+// or "param0", "param1" and so on if neither names them. Routes for the "*" method and
+// for methods OpenAPI can't describe are left out.
+func (mux *ServeMux) OpenAPI(info OpenAPIInfo) ([]byte, error) {
+	doc := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   make(map[string]map[string]*openAPIOperation),
+	}
+
+	schemas := newSchemaGenerator()
+
+	mux.walk("", func(pattern string, node *ServeMux) {
+		names := node.pathParameterNames(countWildcards(pattern))
+
+		for _, method := range node.allowedMethods() {
+			key, ok := openAPIMethods[method]
+			if !ok {
+				continue
+			}
+
+			template := pathTemplate(pattern, names)
+			if doc.Paths[template] == nil {
+				doc.Paths[template] = make(map[string]*openAPIOperation)
+			}
+
+			doc.Paths[template][key] = openAPIOperationFor(node.handlers[method], names, schemas)
+		}
+	})
+
+	if len(schemas.components) > 0 {
+		doc.Components = &openAPIComponents{Schemas: schemas.components}
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// OpenAPIHandler returns a handler that serves the OpenAPI document of the mux,
+// generated when each request is served so it includes routes registered after
+// OpenAPIHandler is called.
+func (mux *ServeMux) OpenAPIHandler(info OpenAPIInfo) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, err := mux.OpenAPI(info)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(doc)
+	})
+}
+
+// pathParameterNames returns names for the n wildcards leading to the node,
+// taken from the first route registered with ParamNames, or otherwise the first
+// route with an Operation naming its path parameters.
+func (mux *ServeMux) pathParameterNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = "param" + strconv.Itoa(i)
+	}
+
+	for _, method := range mux.allowedMethods() {
+		for _, rt := range mux.handlers[method] {
+			if len(rt.paramNames) == n && n > 0 {
+				return rt.paramNames
+			}
+		}
+	}
+
+	for _, method := range mux.allowedMethods() {
+		for _, rt := range mux.handlers[method] {
+			if rt.operation == nil {
+				continue
+			}
+
+			i := 0
+			for _, param := range rt.operation.Parameters {
+				if param.In == "path" && i < n {
+					names[i] = param.Name
+					i++
+				}
+			}
+
+			if i > 0 {
+				return names
+			}
+		}
+	}
+
+	return names
+}
+
+// pathTemplate returns pattern with each wildcard segment replaced by the next
+// name in braces, such as "/posts/{postID}".
+func pathTemplate(pattern string, names []string) string {
+	segments := strings.Split(pattern, "/")
+
+	i := 0
+	for j, segment := range segments {
+		if segment == "*" {
+			segments[j] = "{" + names[i] + "}"
+			i++
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// openAPIOperationFor returns the operation for the routes of a pattern and
+// method, using the metadata of the first route that has it. The media types
+// the routes produce are used as the content types of the responses.
+func openAPIOperationFor(routes []*route, pathNames []string, schemas *schemaGenerator) *openAPIOperation {
+	var op *Operation
+	var mediaTypes []string
+
+	for _, rt := range routes {
+		if op == nil {
+			op = rt.operation
+		}
+
+		if rt.produces != "" {
+			mediaTypes = append(mediaTypes, rt.produces)
+		}
+	}
+
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/json"}
+	}
+
+	if op == nil {
+		op = new(Operation)
+	}
+
+	result := &openAPIOperation{
+		OperationID: op.OperationID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Responses:   make(map[string]*openAPIResponse),
+	}
+
+	var pathParams []Parameter
+	for _, param := range op.Parameters {
+		if param.In == "path" {
+			pathParams = append(pathParams, param)
+		}
+	}
+
+	for i, name := range pathNames {
+		param := Parameter{Name: name, In: "path"}
+		if i < len(pathParams) {
+			param = pathParams[i]
+			param.Name = name
+		}
+
+		param.Required = true
+		result.Parameters = append(result.Parameters, openAPIParameterFor(param, schemas))
+	}
+
+	for _, param := range op.Parameters {
+		if param.In != "path" {
+			result.Parameters = append(result.Parameters, openAPIParameterFor(param, schemas))
+		}
+	}
+
+	if op.RequestBody != nil {
+		result.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content: map[string]*openAPIMediaType{
+				"application/json": {Schema: schemas.schemaFor(reflect.TypeOf(op.RequestBody))},
+			},
+		}
+	}
+
+	for status, response := range op.Responses {
+		description := response.Description
+		if description == "" {
+			description = http.StatusText(status)
+		}
+
+		resp := &openAPIResponse{Description: description}
+		if response.Body != nil {
+			resp.Content = make(map[string]*openAPIMediaType)
+			for _, mediaType := range mediaTypes {
+				resp.Content[mediaType] = &openAPIMediaType{Schema: schemas.schemaFor(reflect.TypeOf(response.Body))}
+			}
+		}
+
+		result.Responses[strconv.Itoa(status)] = resp
+	}
+
+	if len(result.Responses) == 0 {
+		result.Responses["default"] = &openAPIResponse{Description: "Default response"}
+	}
+
+	return result
+}
+
+// openAPIParameterFor returns the OpenAPI description of param.
+func openAPIParameterFor(param Parameter, schemas *schemaGenerator) *openAPIParameter {
+	schema := &jsonSchema{Type: "string"}
+	if param.Schema != nil {
+		schema = schemas.schemaFor(reflect.TypeOf(param.Schema))
+	}
+
+	return &openAPIParameter{
+		Name:        param.Name,
+		In:          param.In,
+		Description: param.Description,
+		Required:    param.Required,
+		Schema:      schema,
+	}
+}
+
+// schemaGenerator generates JSON schemas for Go types, collecting named struct
+// types as components so they can be referenced, including recursively.
+type schemaGenerator struct {
+	components map[string]*jsonSchema
+	names      map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		components: make(map[string]*jsonSchema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor returns the JSON schema of values of type t, as encoded by
+// encoding/json.
+func (g *schemaGenerator) schemaFor(t reflect.Type) *jsonSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &jsonSchema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &jsonSchema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		minimum := 0.0
+		return &jsonSchema{Type: "integer", Minimum: &minimum}
+	case reflect.Float32:
+		return &jsonSchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &jsonSchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &jsonSchema{Type: "string", Format: "byte"}
+		}
+
+		return &jsonSchema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+
+		return &jsonSchema{Ref: "#/components/schemas/" + g.componentName(t)}
+	default:
+		return &jsonSchema{}
+	}
+}
+
+// componentName returns the name of the component for the named type t,
+// generating its schema the first time it is seen.
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := g.components[name]; taken {
+		name = strings.NewReplacer("/", "_", ".", "_").Replace(t.PkgPath()) + "_" + name
+	}
+
+	g.names[t] = name
+	g.components[name] = nil // reserve the name while generating recursive schemas
+	g.components[name] = g.structSchema(t)
+
+	return name
+}
+
+// structSchema returns the object schema of the struct type t, with properties
+// named by json tags. Fields without the omitempty option are required.
+func (g *schemaGenerator) structSchema(t reflect.Type) *jsonSchema {
+	schema := &jsonSchema{Type: "object", Properties: make(map[string]*jsonSchema)}
+	g.addFields(schema, t)
+	return schema
+}
+
+// addFields adds the exported fields of the struct type t to schema, flattening
+// embedded structs without a json name like encoding/json does.
+func (g *schemaGenerator) addFields(schema *jsonSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options := tag, ""
+		if j := strings.IndexByte(tag, ','); j >= 0 {
+			name, options = tag[:j], tag[j+1:]
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			g.addFields(schema, fieldType)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = g.schemaFor(field.Type)
+		if !strings.Contains(","+options+",", ",omitempty,") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package gemux
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// OpenAPIError describes why routes couldn't be registered from an OpenAPI
+// document by HandleOpenAPI.
+type OpenAPIError struct {
+	// MissingHandlers are the operation IDs of the document that have no
+	// handler.
+	MissingHandlers []string
+
+	// ExtraHandlers are the operation IDs that have a handler but aren't in the
+	// document.
+	ExtraHandlers []string
+
+	// MissingOperationIDs are the operations of the document without an
+	// operation ID, such as "GET /posts".
+	MissingOperationIDs []string
+
+	// DuplicateOperationIDs are the operation IDs used by more than one
+	// operation of the document.
+	DuplicateOperationIDs []string
+
+	// UnsupportedPaths are the path templates of the document that the routing
+	// tree can't represent.
+	UnsupportedPaths []UnsupportedPath
+}
+
+// UnsupportedPath is a path template that the routing tree can't represent.
+type UnsupportedPath struct {
+	// Path is the path template, such as "/files/{name}.json".
+	Path string
+
+	// Reason describes why the path template isn't supported.
+	Reason string
+}
+
+// Error returns a description of every problem with the document.
+func (err *OpenAPIError) Error() string {
+	var problems []string
+
+	if len(err.MissingHandlers) > 0 {
+		problems = append(problems, "missing handlers for "+strings.Join(err.MissingHandlers, ", "))
+	}
+
+	if len(err.ExtraHandlers) > 0 {
+		problems = append(problems, "handlers for unknown operations "+strings.Join(err.ExtraHandlers, ", "))
+	}
+
+	if len(err.MissingOperationIDs) > 0 {
+		problems = append(problems, "missing operation IDs for "+strings.Join(err.MissingOperationIDs, ", "))
+	}
+
+	if len(err.DuplicateOperationIDs) > 0 {
+		problems = append(problems, "duplicate operation IDs "+strings.Join(err.DuplicateOperationIDs, ", "))
+	}
+
+	for _, path := range err.UnsupportedPaths {
+		problems = append(problems, "unsupported path "+path.Path+": "+path.Reason)
+	}
+
+	return "gemux: invalid OpenAPI document: " + strings.Join(problems, "; ")
+}
+
+// openAPIPathMethods are the methods of an OpenAPI path item, in the order
+// their operations are registered.
+var openAPIPathMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// loadedOperation is an operation of an OpenAPI document to be registered.
+type loadedOperation struct {
+	pattern    string
+	method     string
+	paramNames []string
+	operation  Operation
+}
+
+// HandleOpenAPI registers a route on the mux for each operation of an OpenAPI 3
+// JSON document, with the handler for its operation ID out of handlers. Path
+// parameters such as "{postID}" become wildcards, named with ParamNames so they
+// can be retrieved with NamedPathParameter. Each route is described with the
+// operation's metadata, and registered with opts.
+//
+// If an operation has no handler, a handler has no operation, an operation has
+// no ID, an ID is used by several operations or a path template can't be
+// represented by the routing tree, an *OpenAPIError listing every problem is
+// returned and no routes are registered.
+func (mux *ServeMux) HandleOpenAPI(doc []byte, handlers map[string]http.Handler, opts ...RouteOption) error {
+	var parsed struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+
+	if err := json.Unmarshal(doc, &parsed); err != nil {
+		return err
+	}
+
+	var operations []loadedOperation
+	loadErr := new(OpenAPIError)
+	seen := make(map[string]bool)
+
+	paths := make([]string, 0, len(parsed.Paths))
+	for path := range parsed.Paths {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pattern, names, reason := patternFromTemplate(path)
+		if reason != "" {
+			loadErr.UnsupportedPaths = append(loadErr.UnsupportedPaths, UnsupportedPath{Path: path, Reason: reason})
+			continue
+		}
+
+		item := parsed.Paths[path]
+
+		var pathParams []loadedParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &pathParams); err != nil {
+				return err
+			}
+		}
+
+		for _, method := range openAPIPathMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+
+			var op struct {
+				OperationID string            `json:"operationId"`
+				Summary     string            `json:"summary"`
+				Description string            `json:"description"`
+				Tags        []string          `json:"tags"`
+				Parameters  []loadedParameter `json:"parameters"`
+			}
+
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return err
+			}
+
+			if op.OperationID == "" {
+				loadErr.MissingOperationIDs = append(loadErr.MissingOperationIDs, strings.ToUpper(method)+" "+path)
+				continue
+			}
+
+			if seen[op.OperationID] {
+				loadErr.DuplicateOperationIDs = appendUnique(loadErr.DuplicateOperationIDs, op.OperationID)
+				continue
+			}
+
+			seen[op.OperationID] = true
+			if handlers[op.OperationID] == nil {
+				loadErr.MissingHandlers = append(loadErr.MissingHandlers, op.OperationID)
+				continue
+			}
+
+			operations = append(operations, loadedOperation{
+				pattern:    pattern,
+				method:     strings.ToUpper(method),
+				paramNames: names,
+				operation: Operation{
+					OperationID: op.OperationID,
+					Summary:     op.Summary,
+					Description: op.Description,
+					Tags:        op.Tags,
+					Parameters:  mergeOpenAPIParameters(pathParams, op.Parameters),
+				},
+			})
+		}
+	}
+
+	for operationID := range handlers {
+		if !seen[operationID] {
+			loadErr.ExtraHandlers = append(loadErr.ExtraHandlers, operationID)
+		}
+	}
+
+	sort.Strings(loadErr.ExtraHandlers)
+	loadErr.UnsupportedPaths = append(loadErr.UnsupportedPaths, duplicateTemplates(paths)...)
+	loadErr.UnsupportedPaths = append(loadErr.UnsupportedPaths, shadowedTemplates(parsed.Paths)...)
+
+	if len(loadErr.MissingHandlers) > 0 || len(loadErr.ExtraHandlers) > 0 || len(loadErr.MissingOperationIDs) > 0 ||
+		len(loadErr.DuplicateOperationIDs) > 0 || len(loadErr.UnsupportedPaths) > 0 {
+		return loadErr
+	}
+
+	for _, op := range operations {
+		routeOpts := []RouteOption{Describe(op.operation)}
+		if len(op.paramNames) > 0 {
+			routeOpts = append(routeOpts, ParamNames(op.paramNames...))
+		}
+
+		mux.Handle(op.pattern, op.method, handlers[op.operation.OperationID], append(routeOpts, opts...)...)
+	}
+
+	return nil
+}
+
+// patternFromTemplate converts an OpenAPI path template such as
+// "/posts/{postID}" to a pattern such as "/posts/*", returning the names of the
+// path parameters. If the template can't be represented, the reason is returned.
+func patternFromTemplate(template string) (string, []string, string) {
+	if !strings.HasPrefix(template, "/") {
+		return "", nil, "path templates must start with /"
+	}
+
+	var names []string
+	segments := strings.Split(template[1:], "/")
+
+	for i, segment := range segments {
+		switch {
+		case segment == "*":
+			return "", nil, "a literal * segment would match like a wildcard"
+		case segment == "." || segment == "..":
+			return "", nil, "dot segments are removed when routing"
+		case segment == "" && i != len(segments)-1:
+			return "", nil, "empty segments are removed when routing"
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			name := segment[1 : len(segment)-1]
+			if name == "" || strings.ContainsAny(name, "{}") {
+				return "", nil, "path parameters must be whole segments"
+			}
+
+			names = append(names, name)
+			segments[i] = "*"
+		case strings.ContainsAny(segment, "{}"):
+			return "", nil, "path parameters must be whole segments"
+		}
+	}
+
+	return "/" + strings.Join(segments, "/"), names, ""
+}
+
+// duplicateTemplates returns the path templates, out of the sorted paths, that
+// are routed to the same node of the routing tree as an earlier template, such
+// as "/posts/" after "/posts" or "/posts/{id}" after "/posts/{postID}".
+// Registering both would make the routes of one replace those of the other.
+func duplicateTemplates(paths []string) []UnsupportedPath {
+	var duplicates []UnsupportedPath
+	nodes := make(map[string]string) // routed path of a node to the first template routed to it
+
+	for _, template := range paths {
+		pattern, _, reason := patternFromTemplate(template)
+		if reason != "" {
+			continue
+		}
+
+		node := ""
+		for head, tail := shiftPath(pattern); head != ""; head, tail = shiftPath(tail) {
+			node += "/" + head
+		}
+
+		if other, ok := nodes[node]; ok {
+			duplicates = append(duplicates, UnsupportedPath{
+				Path:   template,
+				Reason: "routed the same as " + other,
+			})
+			continue
+		}
+
+		nodes[node] = template
+	}
+
+	return duplicates
+}
+
+// shadowedTemplates returns the path templates with a static segment where
+// another template has a path parameter after the same prefix. The routing
+// tree always matches wildcards before static segments, so those templates
+// would never be routed to.
+func shadowedTemplates(paths map[string]map[string]json.RawMessage) []UnsupportedPath {
+	wildcards := make(map[string]string) // pattern prefix to a template with a wildcard after it
+	patterns := make(map[string]string)  // template to pattern
+
+	for template := range paths {
+		pattern, _, reason := patternFromTemplate(template)
+		if reason != "" {
+			continue
+		}
+
+		patterns[template] = pattern
+
+		prefix := ""
+		for head, tail := shiftPath(pattern); head != ""; head, tail = shiftPath(tail) {
+			if head == "*" {
+				if existing, ok := wildcards[prefix]; !ok || template < existing {
+					wildcards[prefix] = template
+				}
+			}
+
+			prefix += "/" + head
+		}
+	}
+
+	var shadowed []UnsupportedPath
+	for template, pattern := range patterns {
+		prefix := ""
+		for head, tail := shiftPath(pattern); head != ""; head, tail = shiftPath(tail) {
+			if other, ok := wildcards[prefix]; ok && head != "*" {
+				shadowed = append(shadowed, UnsupportedPath{
+					Path:   template,
+					Reason: "shadowed by the path parameter of " + other,
+				})
+				break
+			}
+
+			prefix += "/" + head
+		}
+	}
+
+	sort.Slice(shadowed, func(i, j int) bool {
+		return shadowed[i].Path < shadowed[j].Path
+	})
+
+	return shadowed
+}
+
+// loadedParameter is the part of an OpenAPI parameter used by HandleOpenAPI.
+type loadedParameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+}
+
+// parameter returns the Parameter described by the loaded parameter.
+func (param loadedParameter) parameter() Parameter {
+	return Parameter{
+		Name:        param.Name,
+		In:          param.In,
+		Description: param.Description,
+		Required:    param.Required,
+	}
+}
+
+// mergeOpenAPIParameters returns the parameters of an operation, along with
+// the parameters of its path item that the operation doesn't override.
+// Parameters without a name, such as references, are left out.
+func mergeOpenAPIParameters(pathParams, opParams []loadedParameter) []Parameter {
+	var params []Parameter
+	overridden := make(map[string]bool)
+
+	for _, param := range opParams {
+		overridden[param.In+":"+param.Name] = true
+	}
+
+	for _, param := range pathParams {
+		if param.Name != "" && !overridden[param.In+":"+param.Name] {
+			params = append(params, param.parameter())
+		}
+	}
+
+	for _, param := range opParams {
+		if param.Name != "" {
+			params = append(params, param.parameter())
+		}
+	}
+
+	return params
+}
+
+// appendUnique appends value to values unless it is already in them.
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
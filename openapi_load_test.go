@@ -0,0 +1,218 @@
+package gemux
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const openAPILoadTestDocument = `{
+  "openapi": "3.0.3",
+  "info": {"title": "Posts", "version": "1.0.0"},
+  "paths": {
+    "/posts": {
+      "get": {"operationId": "listPosts", "summary": "List posts"},
+      "post": {"operationId": "createPost"}
+    },
+    "/posts/{postID}/comments/{commentID}": {
+      "parameters": [
+        {"name": "postID", "in": "path", "required": true, "description": "ID of the post"},
+        {"name": "commentID", "in": "path", "required": true}
+      ],
+      "get": {
+        "operationId": "getComment",
+        "parameters": [
+          {"name": "commentID", "in": "path", "required": true, "description": "ID of the comment"},
+          {"$ref": "#/components/parameters/Limit"}
+        ],
+        "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"type": ["object", "null"]}}}}}
+      }
+    }
+  }
+}`
+
+func TestHandleOpenAPI(t *testing.T) {
+	mux := new(ServeMux)
+
+	err := mux.HandleOpenAPI([]byte(openAPILoadTestDocument), map[string]http.Handler{
+		"listPosts":  stringHandler("list posts"),
+		"createPost": stringHandler("create post"),
+		"getComment": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			fmt.Fprintf(w, "%s %s", NamedPathParameter(ctx, "postID"), NamedPathParameter(ctx, "commentID"))
+		}),
+	}, Header("X-Api-Key", "secret"))
+	if err != nil {
+		t.Fatalf("did not expect error loading document: %v", err)
+	}
+
+	cases := []struct {
+		requestURL           string
+		requestMethod        string
+		expectedResponseBody string
+	}{
+		{"/posts", http.MethodGet, "list posts"},
+		{"/posts", http.MethodPost, "create post"},
+		{"/posts/4/comments/2", http.MethodGet, "4 2"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.requestMethod+" "+tt.requestURL, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.requestMethod, tt.requestURL, nil)
+			req.Header.Set("X-Api-Key", "secret")
+
+			mux.ServeHTTP(rw, req)
+
+			if body := rw.Body.String(); body != tt.expectedResponseBody {
+				t.Errorf("expected response body %q, got %q", tt.expectedResponseBody, body)
+			}
+		})
+	}
+
+	routes := mux.Routes()
+	if len(routes) != 3 {
+		t.Fatalf("expected 3 routes, got %d", len(routes))
+	}
+
+	comment := routes[2]
+	if comment.Pattern != "/posts/*/comments/*" || !reflect.DeepEqual(comment.ParamNames, []string{"postID", "commentID"}) {
+		t.Errorf("expected named comment route, got %s %v", comment.Pattern, comment.ParamNames)
+	}
+
+	expectedParams := []Parameter{
+		{Name: "postID", In: "path", Required: true, Description: "ID of the post"},
+		{Name: "commentID", In: "path", Required: true, Description: "ID of the comment"},
+	}
+
+	if comment.Operation == nil || !reflect.DeepEqual(comment.Operation.Parameters, expectedParams) {
+		t.Errorf("expected operation parameters %v, got %+v", expectedParams, comment.Operation)
+	}
+}
+
+func TestHandleOpenAPIErrors(t *testing.T) {
+	doc := `{
+  "paths": {
+    "/posts": {"get": {"operationId": "listPosts"}, "post": {}},
+    "/posts/": {"put": {"operationId": "replacePosts"}},
+    "/posts/latest": {"get": {"operationId": "latestPost"}},
+    "/posts/{postID}": {"get": {"operationId": "getPost"}},
+    "/files/{name}.json": {"get": {"operationId": "getFile"}},
+    "/a/*": {"get": {"operationId": "star"}},
+    "/users": {"get": {"operationId": "getPost"}}
+  }
+}`
+
+	mux := new(ServeMux)
+	err := mux.HandleOpenAPI([]byte(doc), map[string]http.Handler{
+		"listPosts":    stringHandler("a"),
+		"getPost":      stringHandler("b"),
+		"latestPost":   stringHandler("c"),
+		"deletePost":   stringHandler("d"),
+		"replacePosts": stringHandler("e"),
+	})
+
+	openAPIErr, ok := err.(*OpenAPIError)
+	if !ok {
+		t.Fatalf("expected *OpenAPIError, got %v", err)
+	}
+
+	expected := &OpenAPIError{
+		ExtraHandlers:         []string{"deletePost"},
+		MissingOperationIDs:   []string{"POST /posts"},
+		DuplicateOperationIDs: []string{"getPost"},
+		UnsupportedPaths: []UnsupportedPath{
+			{Path: "/a/*", Reason: "a literal * segment would match like a wildcard"},
+			{Path: "/files/{name}.json", Reason: "path parameters must be whole segments"},
+			{Path: "/posts/", Reason: "routed the same as /posts"},
+			{Path: "/posts/latest", Reason: "shadowed by the path parameter of /posts/{postID}"},
+		},
+	}
+
+	if !reflect.DeepEqual(openAPIErr, expected) {
+		t.Errorf("expected error %+v, got %+v", expected, openAPIErr)
+	}
+
+	if routes := mux.Routes(); len(routes) != 0 {
+		t.Errorf("expected no routes to be registered, got %v", routes)
+	}
+}
+
+func TestHandleOpenAPIDuplicateTemplates(t *testing.T) {
+	cases := []struct {
+		name     string
+		doc      string
+		expected []UnsupportedPath
+	}{
+		{
+			name:     "trailing slash",
+			doc:      `{"paths": {"/posts": {"get": {"operationId": "a"}}, "/posts/": {"get": {"operationId": "b"}}}}`,
+			expected: []UnsupportedPath{{Path: "/posts/", Reason: "routed the same as /posts"}},
+		},
+		{
+			name:     "parameter names",
+			doc:      `{"paths": {"/posts/{id}": {"get": {"operationId": "a"}}, "/posts/{postID}": {"delete": {"operationId": "b"}}}}`,
+			expected: []UnsupportedPath{{Path: "/posts/{postID}", Reason: "routed the same as /posts/{id}"}},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := new(ServeMux)
+			err := mux.HandleOpenAPI([]byte(tt.doc), map[string]http.Handler{"a": stringHandler("a"), "b": stringHandler("b")})
+
+			openAPIErr, ok := err.(*OpenAPIError)
+			if !ok {
+				t.Fatalf("expected *OpenAPIError, got %v", err)
+			}
+
+			if !reflect.DeepEqual(openAPIErr.UnsupportedPaths, tt.expected) {
+				t.Errorf("expected unsupported paths %+v, got %+v", tt.expected, openAPIErr.UnsupportedPaths)
+			}
+
+			if routes := mux.Routes(); len(routes) != 0 {
+				t.Errorf("expected no routes to be registered, got %v", routes)
+			}
+		})
+	}
+}
+
+func TestHandleOpenAPIMissingHandler(t *testing.T) {
+	err := new(ServeMux).HandleOpenAPI([]byte(`{"paths": {"/posts": {"get": {"operationId": "listPosts"}}}}`), nil)
+
+	expected := "gemux: invalid OpenAPI document: missing handlers for listPosts"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestPatternFromTemplate(t *testing.T) {
+	cases := []struct {
+		template        string
+		expectedPattern string
+		expectedNames   []string
+		expectedReason  string
+	}{
+		{"/", "/", nil, ""},
+		{"/posts/", "/posts/", nil, ""},
+		{"/posts/{postID}/comments/{commentID}", "/posts/*/comments/*", []string{"postID", "commentID"}, ""},
+		{"posts", "", nil, "path templates must start with /"},
+		{"/posts//comments", "", nil, "empty segments are removed when routing"},
+		{"/posts/../comments", "", nil, "dot segments are removed when routing"},
+		{"/posts/{}", "", nil, "path parameters must be whole segments"},
+		{"/posts/{a}{b}", "", nil, "path parameters must be whole segments"},
+		{"/v{version}/posts", "", nil, "path parameters must be whole segments"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.template, func(t *testing.T) {
+			pattern, names, reason := patternFromTemplate(tt.template)
+			if pattern != tt.expectedPattern || !reflect.DeepEqual(names, tt.expectedNames) || reason != tt.expectedReason {
+				t.Errorf("expected (%q, %v, %q), got (%q, %v, %q)", tt.expectedPattern, tt.expectedNames,
+					tt.expectedReason, pattern, names, reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package gemux
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type openAPITestPost struct {
+	ID        int64              `json:"id"`
+	Title     string             `json:"title"`
+	Tags      []string           `json:"tags,omitempty"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Author    *openAPITestAuthor `json:"author,omitempty"`
+	Replies   []openAPITestPost  `json:"replies,omitempty"`
+	internal  string
+}
+
+type openAPITestAuthor struct {
+	Name  string            `json:"name"`
+	Extra map[string]string `json:"extra,omitempty"`
+	Skip  string            `json:"-"`
+}
+
+type openAPITestCreatePost struct {
+	openAPITestAuthorRef
+	Title string `json:"title"`
+}
+
+type openAPITestAuthorRef struct {
+	AuthorID uint `json:"authorId"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Handle("/posts", http.MethodGet, stringHandler("a"), Describe(Operation{
+		OperationID: "listPosts",
+		Summary:     "List posts",
+		Parameters: []Parameter{
+			{Name: "limit", In: "query", Schema: 0},
+		},
+		Responses: map[int]Response{
+			http.StatusOK: {Body: []openAPITestPost{}},
+		},
+	}))
+	mux.Handle("/posts", http.MethodPost, stringHandler("b"), Describe(Operation{
+		OperationID: "createPost",
+		RequestBody: openAPITestCreatePost{},
+		Responses: map[int]Response{
+			http.StatusCreated: {Description: "The created post", Body: &openAPITestPost{}},
+		},
+	}))
+	mux.Handle("/posts/*/comments/*", http.MethodDelete, stringHandler("c"), Describe(Operation{
+		OperationID: "deleteComment",
+		Parameters: []Parameter{
+			{Name: "postID", In: "path", Description: "ID of the post", Schema: int64(0)},
+			{Name: "commentID", In: "path"},
+		},
+	}))
+	mux.Handle("/posts/*/comments/*", http.MethodGet, stringHandler("d"), Produces("text/csv"), Describe(Operation{
+		Responses: map[int]Response{http.StatusOK: {Body: ""}},
+	}))
+	mux.Handle("/posts/*/comments/*", http.MethodGet, stringHandler("e"), Produces("application/json"))
+	mux.Handle("/users/*", http.MethodGet, stringHandler("f"))
+	mux.Handle("/users/*", "PROPFIND", stringHandler("g"))
+	mux.Handle("/any", "*", stringHandler("h"))
+	mux.Handle("/files/a*b/*", http.MethodGet, stringHandler("i"), ParamNames("name"))
+
+	raw, err := mux.OpenAPI(OpenAPIInfo{Title: "Test", Version: "1.0.0"})
+	if err != nil {
+		t.Fatalf("did not expect error generating document: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("did not expect error decoding document: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": "Test", "version": "1.0.0"},
+		"paths": map[string]interface{}{
+			"/posts": map[string]interface{}{
+				"get": map[string]interface{}{
+					"operationId": "listPosts",
+					"summary":     "List posts",
+					"parameters": []interface{}{
+						map[string]interface{}{
+							"name":   "limit",
+							"in":     "query",
+							"schema": map[string]interface{}{"type": "integer", "format": "int32"},
+						},
+					},
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"description": "OK",
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]interface{}{
+										"type":  "array",
+										"items": map[string]interface{}{"$ref": "#/components/schemas/openAPITestPost"},
+									},
+								},
+							},
+						},
+					},
+				},
+				"post": map[string]interface{}{
+					"operationId": "createPost",
+					"requestBody": map[string]interface{}{
+						"required": true,
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{
+								"schema": map[string]interface{}{"$ref": "#/components/schemas/openAPITestCreatePost"},
+							},
+						},
+					},
+					"responses": map[string]interface{}{
+						"201": map[string]interface{}{
+							"description": "The created post",
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]interface{}{"$ref": "#/components/schemas/openAPITestPost"},
+								},
+							},
+						},
+					},
+				},
+			},
+			"/posts/{postID}/comments/{commentID}": map[string]interface{}{
+				"delete": map[string]interface{}{
+					"operationId": "deleteComment",
+					"parameters": []interface{}{
+						map[string]interface{}{
+							"name":        "postID",
+							"in":          "path",
+							"description": "ID of the post",
+							"required":    true,
+							"schema":      map[string]interface{}{"type": "integer", "format": "int64"},
+						},
+						map[string]interface{}{
+							"name":     "commentID",
+							"in":       "path",
+							"required": true,
+							"schema":   map[string]interface{}{"type": "string"},
+						},
+					},
+					"responses": map[string]interface{}{
+						"default": map[string]interface{}{"description": "Default response"},
+					},
+				},
+				"get": map[string]interface{}{
+					"parameters": []interface{}{
+						map[string]interface{}{
+							"name":     "postID",
+							"in":       "path",
+							"required": true,
+							"schema":   map[string]interface{}{"type": "string"},
+						},
+						map[string]interface{}{
+							"name":     "commentID",
+							"in":       "path",
+							"required": true,
+							"schema":   map[string]interface{}{"type": "string"},
+						},
+					},
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"description": "OK",
+							"content": map[string]interface{}{
+								"text/csv":         map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+								"application/json": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+							},
+						},
+					},
+				},
+			},
+			"/files/a*b/{name}": map[string]interface{}{
+				"get": map[string]interface{}{
+					"parameters": []interface{}{
+						map[string]interface{}{
+							"name":     "name",
+							"in":       "path",
+							"required": true,
+							"schema":   map[string]interface{}{"type": "string"},
+						},
+					},
+					"responses": map[string]interface{}{
+						"default": map[string]interface{}{"description": "Default response"},
+					},
+				},
+			},
+			"/users/{param0}": map[string]interface{}{
+				"get": map[string]interface{}{
+					"parameters": []interface{}{
+						map[string]interface{}{
+							"name":     "param0",
+							"in":       "path",
+							"required": true,
+							"schema":   map[string]interface{}{"type": "string"},
+						},
+					},
+					"responses": map[string]interface{}{
+						"default": map[string]interface{}{"description": "Default response"},
+					},
+				},
+			},
+		},
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"openAPITestPost": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"id":        map[string]interface{}{"type": "integer", "format": "int64"},
+						"title":     map[string]interface{}{"type": "string"},
+						"tags":      map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+						"createdAt": map[string]interface{}{"type": "string", "format": "date-time"},
+						"author":    map[string]interface{}{"$ref": "#/components/schemas/openAPITestAuthor"},
+						"replies": map[string]interface{}{
+							"type":  "array",
+							"items": map[string]interface{}{"$ref": "#/components/schemas/openAPITestPost"},
+						},
+					},
+					"required": []interface{}{"id", "title", "createdAt"},
+				},
+				"openAPITestAuthor": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name": map[string]interface{}{"type": "string"},
+						"extra": map[string]interface{}{
+							"type":                 "object",
+							"additionalProperties": map[string]interface{}{"type": "string"},
+						},
+					},
+					"required": []interface{}{"name"},
+				},
+				"openAPITestCreatePost": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"authorId": map[string]interface{}{"type": "integer", "minimum": 0.0},
+						"title":    map[string]interface{}{"type": "string"},
+					},
+					"required": []interface{}{"authorId", "title"},
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(doc, expected) {
+		actual, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("unexpected document:\n%s", actual)
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Handle("/openapi.json", http.MethodGet, mux.OpenAPIHandler(OpenAPIInfo{Title: "Test", Version: "1"}))
+	mux.Handle("/posts", http.MethodGet, stringHandler("a"))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	if contentType := rw.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected content type %q, got %q", "application/json", contentType)
+	}
+
+	var doc struct {
+		Paths map[string]interface{} `json:"paths"`
+	}
+
+	if err := json.Unmarshal(rw.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("did not expect error decoding document: %v", err)
+	}
+
+	if _, ok := doc.Paths["/posts"]; !ok {
+		t.Errorf("expected document to include routes registered after the handler, got %v", doc.Paths)
+	}
+}
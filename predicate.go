@@ -0,0 +1,243 @@
+package gemux
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// predicate is a condition on a request other than its path and method.
+type predicate struct {
+	key    string // identifies the predicate, so re-registrations can replace a route
+	status int    // status code to reply with when no route matches because of this predicate
+	match  func(r *http.Request) bool
+}
+
+// Header returns a RouteOption that only matches requests where the header key
+// contains value, either as the whole header or as one of its comma separated
+// elements. If value is a media type without parameters, such as
+// "application/vnd.foo.v2+json", elements match it regardless of their
+// parameters, so "application/vnd.foo.v2+json; q=0.9" matches too. If no route
+// matches because of a Header predicate, the mux replies with
+// NotAcceptableHandler.
+func Header(key, value string) RouteOption {
+	key = http.CanonicalHeaderKey(key)
+
+	var mediaType string
+	if strings.Contains(value, "/") && !strings.Contains(value, ";") {
+		mediaType, _, _ = mime.ParseMediaType(value)
+	}
+
+	return withPredicate(predicate{
+		key:    "header:" + key + "=" + value,
+		status: http.StatusNotAcceptable,
+		match: func(r *http.Request) bool {
+			for _, line := range r.Header[key] {
+				if line == value {
+					return true
+				}
+
+				for _, element := range strings.Split(line, ",") {
+					element = strings.TrimSpace(element)
+					if element == value {
+						return true
+					}
+
+					if mediaType != "" && strings.Contains(element, ";") {
+						if actual, _, err := mime.ParseMediaType(element); err == nil && actual == mediaType {
+							return true
+						}
+					}
+				}
+			}
+
+			return false
+		},
+	})
+}
+
+// Query returns a RouteOption that only matches requests where the URL query
+// parameter key has value. If no route matches because of a Query predicate,
+// the mux replies with NotAcceptableHandler.
+func Query(key, value string) RouteOption {
+	return withPredicate(predicate{
+		key:    "query:" + key + "=" + value,
+		status: http.StatusNotAcceptable,
+		match: func(r *http.Request) bool {
+			for _, actual := range r.URL.Query()[key] {
+				if actual == value {
+					return true
+				}
+			}
+
+			return false
+		},
+	})
+}
+
+// Scheme returns a RouteOption that only matches requests made over the given
+// scheme, either "http" or "https". If no route matches because of a Scheme
+// predicate, the mux replies with NotAcceptableHandler.
+func Scheme(scheme string) RouteOption {
+	scheme = strings.ToLower(scheme)
+
+	return withPredicate(predicate{
+		key:    "scheme:" + scheme,
+		status: http.StatusNotAcceptable,
+		match: func(r *http.Request) bool {
+			return requestScheme(r) == scheme
+		},
+	})
+}
+
+// ContentType returns a RouteOption that only matches requests with a body of
+// the given media type, such as "application/json". A subtype of "*" matches
+// any subtype, so "image/*" matches "image/png". Media type parameters are
+// ignored. If no route matches because of a ContentType predicate, the mux
+// replies with UnsupportedMediaTypeHandler.
+func ContentType(mediaType string) RouteOption {
+	mediaType = strings.ToLower(mediaType)
+
+	return withPredicate(predicate{
+		key:    "content-type:" + mediaType,
+		status: http.StatusUnsupportedMediaType,
+		match: func(r *http.Request) bool {
+			actual, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil {
+				return false
+			}
+
+			return mediaTypeMatches(mediaType, actual)
+		},
+	})
+}
+
+// withPredicate returns a RouteOption that adds p to a route.
+func withPredicate(p predicate) RouteOption {
+	return func(rt *route) {
+		rt.predicates = append(rt.predicates, p)
+	}
+}
+
+// requestScheme returns the lowercase scheme the request was made with.
+func requestScheme(r *http.Request) string {
+	if r.URL.Scheme != "" {
+		return strings.ToLower(r.URL.Scheme)
+	}
+
+	if r.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
+
+// mediaTypeMatches reports whether the media type actual is described by the
+// lowercase media range pattern, which may be "*/*" or have a "*" subtype.
+func mediaTypeMatches(pattern, actual string) bool {
+	if pattern == "*/*" || pattern == actual {
+		return true
+	}
+
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(actual, pattern[:len(pattern)-1])
+	}
+
+	return false
+}
+
+// matches reports whether the request satisfies every predicate of the route.
+// If it doesn't, the status of the first failing predicate is returned.
+func (rt *route) matches(r *http.Request) (bool, int) {
+	for _, p := range rt.predicates {
+		if !p.match(r) {
+			return false, p.status
+		}
+	}
+
+	return true, 0
+}
+
+// specificity is used to order routes, routes with more predicates are tried
+// first.
+func (rt *route) specificity() int {
+	return len(rt.predicates)
+}
+
+// predicateKey returns a string identifying the set of predicates on the route,
+// independent of the order they were given in.
+func (rt *route) predicateKey() string {
+	keys := make([]string, len(rt.predicates))
+	for i, p := range rt.predicates {
+		keys[i] = p.key
+	}
+
+	sort.Strings(keys)
+	return strings.Join(keys, "\x00")
+}
+
+// addRoute adds rt to routes, which are kept in order of decreasing specificity.
+// A route with the same predicates as rt is replaced, and returned.
+func addRoute(routes []*route, rt *route) ([]*route, *route) {
+	key := rt.predicateKey()
+	for i, existing := range routes {
+		if existing.predicateKey() == key {
+			routes[i] = rt
+			return routes, existing
+		}
+	}
+
+	i := sort.Search(len(routes), func(i int) bool {
+		return routes[i].specificity() < rt.specificity()
+	})
+
+	routes = append(routes, nil)
+	copy(routes[i+1:], routes[i:])
+	routes[i] = rt
+
+	return routes, nil
+}
+
+// selectRoute returns the most specific route out of the lists of routes that
+// matches the request. Routes of equal specificity that produce different
+// media types are chosen between using the Accept header, and otherwise earlier
+// routes are preferred. If no route matches, the status code to reply with is
+// returned instead, which is http.StatusUnsupportedMediaType if any route failed
+// to match because of its content type and http.StatusNotAcceptable otherwise.
+func selectRoute(r *http.Request, routeLists ...[]*route) (*route, int) {
+	var best *route
+	status := http.StatusNotAcceptable
+
+	for _, routes := range routeLists {
+		for _, rt := range routes {
+			if best != nil && rt.specificity() < best.specificity() {
+				break
+			}
+
+			ok, failed := rt.matches(r)
+			if !ok {
+				if failed == http.StatusUnsupportedMediaType {
+					status = failed
+				}
+
+				continue
+			}
+
+			if best == nil || rt.specificity() > best.specificity() {
+				best = rt
+				continue
+			}
+
+			if best.produces != "" && rt.produces != "" && negotiatesBetter(r, rt.produces, best.produces) {
+				best = rt
+			}
+		}
+	}
+
+	if best != nil {
+		return best, 0
+	}
+
+	return nil, status
+}
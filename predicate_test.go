@@ -0,0 +1,226 @@
+package gemux
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPredicates(t *testing.T) {
+	type registration struct {
+		method  string
+		handler http.Handler
+		opts    []RouteOption
+	}
+
+	cases := []struct {
+		name                 string
+		register             []registration
+		requestURL           string
+		requestMethod        string
+		requestHeader        http.Header
+		requestTLS           bool
+		expectedResponseCode int
+		expectedResponseBody string
+	}{
+		{
+			name: "header",
+			register: []registration{
+				{http.MethodGet, stringHandler("v1"), nil},
+				{http.MethodGet, stringHandler("v2"), []RouteOption{Header("Accept", "application/vnd.foo.v2+json")}},
+			},
+			requestURL:           "/foo",
+			requestMethod:        http.MethodGet,
+			requestHeader:        http.Header{"Accept": {"text/plain, application/vnd.foo.v2+json"}},
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "v2",
+		},
+		{
+			name: "header media type with parameters",
+			register: []registration{
+				{http.MethodGet, stringHandler("v2"), []RouteOption{Header("Accept", "application/vnd.foo.v2+json")}},
+			},
+			requestURL:           "/foo",
+			requestMethod:        http.MethodGet,
+			requestHeader:        http.Header{"Accept": {"text/plain, application/vnd.foo.v2+json; q=0.9"}},
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "v2",
+		},
+		{
+			name: "header media type with charset",
+			register: []registration{
+				{http.MethodGet, stringHandler("v2"), []RouteOption{Header("Accept", "application/vnd.foo.v2+json")}},
+			},
+			requestURL:           "/foo",
+			requestMethod:        http.MethodGet,
+			requestHeader:        http.Header{"Accept": {"application/vnd.foo.v2+json;charset=utf-8"}},
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "v2",
+		},
+		{
+			name: "header media type parameters don't match other types",
+			register: []registration{
+				{http.MethodGet, stringHandler("v2"), []RouteOption{Header("Accept", "application/vnd.foo.v2+json")}},
+			},
+			requestURL:           "/foo",
+			requestMethod:        http.MethodGet,
+			requestHeader:        http.Header{"Accept": {"application/vnd.foo.v3+json; q=0.9"}},
+			expectedResponseCode: http.StatusNotAcceptable,
+			expectedResponseBody: "406 not acceptable\n",
+		},
+		{
+			name: "header falls back to route without predicates",
+			register: []registration{
+				{http.MethodGet, stringHandler("v1"), nil},
+				{http.MethodGet, stringHandler("v2"), []RouteOption{Header("Accept", "application/vnd.foo.v2+json")}},
+			},
+			requestURL:           "/foo",
+			requestMethod:        http.MethodGet,
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "v1",
+		},
+		{
+			name: "query",
+			register: []registration{
+				{http.MethodGet, stringHandler("v1"), []RouteOption{Query("version", "1")}},
+				{http.MethodGet, stringHandler("v2"), []RouteOption{Query("version", "2")}},
+			},
+			requestURL:           "/foo?version=2",
+			requestMethod:        http.MethodGet,
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "v2",
+		},
+		{
+			name: "most specific",
+			register: []registration{
+				{http.MethodGet, stringHandler("a"), []RouteOption{Query("version", "2")}},
+				{http.MethodGet, stringHandler("b"), []RouteOption{Query("version", "2"), Header("X-Beta", "true")}},
+			},
+			requestURL:           "/foo?version=2",
+			requestMethod:        http.MethodGet,
+			requestHeader:        http.Header{"X-Beta": {"true"}},
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "b",
+		},
+		{
+			name: "specific method beats wildcard method",
+			register: []registration{
+				{"*", stringHandler("a"), nil},
+				{http.MethodGet, stringHandler("b"), []RouteOption{Query("version", "2")}},
+			},
+			requestURL:           "/foo?version=2",
+			requestMethod:        http.MethodGet,
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "b",
+		},
+		{
+			name: "re-registration replaces",
+			register: []registration{
+				{http.MethodGet, stringHandler("a"), []RouteOption{Query("a", "1"), Query("b", "2")}},
+				{http.MethodGet, stringHandler("b"), []RouteOption{Query("b", "2"), Query("a", "1")}},
+			},
+			requestURL:           "/foo?a=1&b=2",
+			requestMethod:        http.MethodGet,
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "b",
+		},
+		{
+			name: "scheme",
+			register: []registration{
+				{http.MethodGet, stringHandler("http"), []RouteOption{Scheme("http")}},
+				{http.MethodGet, stringHandler("https"), []RouteOption{Scheme("HTTPS")}},
+			},
+			requestURL:           "/foo",
+			requestMethod:        http.MethodGet,
+			requestTLS:           true,
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "https",
+		},
+		{
+			name: "content type",
+			register: []registration{
+				{http.MethodPost, stringHandler("json"), []RouteOption{ContentType("application/json")}},
+				{http.MethodPost, stringHandler("image"), []RouteOption{ContentType("image/*")}},
+			},
+			requestURL:           "/foo",
+			requestMethod:        http.MethodPost,
+			requestHeader:        http.Header{"Content-Type": {"image/png"}},
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "image",
+		},
+		{
+			name: "content type with parameters",
+			register: []registration{
+				{http.MethodPost, stringHandler("json"), []RouteOption{ContentType("application/json")}},
+			},
+			requestURL:           "/foo",
+			requestMethod:        http.MethodPost,
+			requestHeader:        http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+			expectedResponseCode: http.StatusOK,
+			expectedResponseBody: "json",
+		},
+		{
+			name: "not acceptable",
+			register: []registration{
+				{http.MethodGet, stringHandler("v2"), []RouteOption{Query("version", "2")}},
+			},
+			requestURL:           "/foo?version=3",
+			requestMethod:        http.MethodGet,
+			expectedResponseCode: http.StatusNotAcceptable,
+			expectedResponseBody: "406 not acceptable\n",
+		},
+		{
+			name: "unsupported media type",
+			register: []registration{
+				{http.MethodPost, stringHandler("json"), []RouteOption{ContentType("application/json")}},
+			},
+			requestURL:           "/foo",
+			requestMethod:        http.MethodPost,
+			requestHeader:        http.Header{"Content-Type": {"text/plain"}},
+			expectedResponseCode: http.StatusUnsupportedMediaType,
+			expectedResponseBody: "415 unsupported media type\n",
+		},
+		{
+			name: "method not allowed",
+			register: []registration{
+				{http.MethodPost, stringHandler("json"), []RouteOption{ContentType("application/json")}},
+			},
+			requestURL:           "/foo",
+			requestMethod:        http.MethodGet,
+			expectedResponseCode: http.StatusMethodNotAllowed,
+			expectedResponseBody: "405 method not allowed\n",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := new(ServeMux)
+
+			for _, route := range tt.register {
+				mux.Handle("/foo", route.method, route.handler, route.opts...)
+			}
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.requestMethod, tt.requestURL, strings.NewReader(""))
+			for key, values := range tt.requestHeader {
+				req.Header[key] = values
+			}
+
+			if tt.requestTLS {
+				req.TLS = &tls.ConnectionState{}
+			}
+
+			mux.ServeHTTP(rw, req)
+
+			if rw.Code != tt.expectedResponseCode {
+				t.Errorf("expected response code %d, got %d", tt.expectedResponseCode, rw.Code)
+			}
+
+			if body := rw.Body.String(); body != tt.expectedResponseBody {
+				t.Errorf("expected response body %q, got %q", tt.expectedResponseBody, body)
+			}
+		})
+	}
+}
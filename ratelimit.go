@@ -0,0 +1,179 @@
+package gemux
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate of requests for each key, such as the client IP,
+// with an in-memory token bucket per key. Attach it to routes or groups with
+// the RateLimit option. It is safe for concurrent use.
+type RateLimiter struct {
+	limit int
+	per   time.Duration
+	key   func(r *http.Request) string
+	now   func() time.Time
+	mu    sync.Mutex
+	keys  map[string]*tokenBucket
+	swept time.Time // when idle buckets were last removed
+}
+
+// tokenBucket holds the tokens left for a key as of last.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter that allows bursts of limit requests
+// for each key, refilled at a rate of limit requests per the given duration.
+// The key of a request is returned by key, which can use PathParameter, the
+// request headers or RemoteIP. Requests with an empty key aren't limited.
+// NewRateLimiter panics if limit or per isn't positive.
+func NewRateLimiter(limit int, per time.Duration, key func(r *http.Request) string) *RateLimiter {
+	if limit <= 0 || per <= 0 {
+		panic("gemux: rate limits must be positive")
+	}
+
+	return &RateLimiter{
+		limit: limit,
+		per:   per,
+		key:   key,
+		now:   time.Now,
+		keys:  make(map[string]*tokenBucket),
+	}
+}
+
+// RemoteIP returns the IP address of the client that sent the request, from
+// its RemoteAddr. It can be used as the key of a RateLimiter.
+func RemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// Allow reports whether the request is within the rate limit, taking a token
+// from the bucket of its key if it is. If it isn't, Allow returns how long to
+// wait until a token is available.
+func (l *RateLimiter) Allow(r *http.Request) (bool, time.Duration) {
+	key := l.key(r)
+	if key == "" {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.keys[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(l.limit), last: now}
+		l.keys[key] = bucket
+	}
+
+	bucket.tokens = math.Min(float64(l.limit), bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate())
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	return false, time.Duration((1 - bucket.tokens) / l.rate() * float64(time.Second))
+}
+
+// refund returns the token taken by Allow for the request to the bucket of its
+// key, for a request that was rejected by another limiter after this one
+// allowed it.
+func (l *RateLimiter) refund(r *http.Request) {
+	key := l.key(r)
+	if key == "" {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if bucket, ok := l.keys[key]; ok {
+		bucket.tokens = math.Min(float64(l.limit), bucket.tokens+1)
+	}
+}
+
+// rate returns the number of tokens added to each bucket per second.
+func (l *RateLimiter) rate() float64 {
+	return float64(l.limit) / l.per.Seconds()
+}
+
+// sweep removes the buckets that have been idle long enough to be full again,
+// at most once per refill period, so buckets of keys that are no longer seen
+// don't accumulate.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < l.per {
+		return
+	}
+
+	l.swept = now
+	for key, bucket := range l.keys {
+		if now.Sub(bucket.last) >= l.per {
+			delete(l.keys, key)
+		}
+	}
+}
+
+// RateLimit returns a RouteOption that limits the rate of requests to the
+// route with limiter. Requests over the limit are replied to with the
+// TooManyRequestsHandler of the mux, after setting the Retry-After header.
+// RateLimit can be given several times, such as to a group and a route of the
+// group, in which case requests have to be within every limit. A limiter can
+// be shared between routes, which then share their limits.
+func RateLimit(limiter *RateLimiter) RouteOption {
+	return func(rt *route) {
+		rt.rateLimiters = append(rt.rateLimiters, limiter)
+	}
+}
+
+// tooManyRequestsHandler returns the mux TooManyRequestsHandler if there is
+// one, otherwise TooManyRequestsHandler.
+func (mux *ServeMux) tooManyRequestsHandler() http.Handler {
+	if mux.TooManyRequestsHandler != nil {
+		return mux.TooManyRequestsHandler
+	}
+
+	return TooManyRequestsHandler()
+}
+
+// TooManyRequestsHandler returns a simple request handler that replies to each
+// request with a "429 too many requests" reply and writes the 429 status code.
+func TooManyRequestsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "429 too many requests", http.StatusTooManyRequests)
+	})
+}
+
+// allowRequest reports whether the request is within the limits of the rate
+// limiters of rt, replying with the too many requests handler if it isn't. The
+// tokens taken by the limiters that allowed a rejected request are refunded, so
+// it only counts against the limit that rejected it.
+func (mux *ServeMux) allowRequest(w http.ResponseWriter, r *http.Request, rt *route) bool {
+	for i, limiter := range rt.rateLimiters {
+		if ok, wait := limiter.Allow(r); !ok {
+			for _, allowed := range rt.rateLimiters[:i] {
+				allowed.refund(r)
+			}
+
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			mux.tooManyRequestsHandler().ServeHTTP(w, r)
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,197 @@
+package gemux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock for rate limiters that only moves when advanced.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestRateLimit(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	login := NewRateLimiter(2, time.Minute, RemoteIP)
+	login.now = clock.Now
+
+	reports := NewRateLimiter(1, time.Second, func(r *http.Request) string {
+		return PathParameter(r.Context(), 0)
+	})
+	reports.now = clock.Now
+
+	mux := new(ServeMux)
+	mux.Handle("/login", http.MethodPost, stringHandler("logged in"), RateLimit(login))
+	events := mux.Group("/events", RateLimit(reports))
+	events.Handle("/*/reports/*", http.MethodGet, stringHandler("report"))
+
+	steps := []struct {
+		name               string
+		advance            time.Duration
+		requestURL         string
+		requestMethod      string
+		remoteAddr         string
+		expectedStatus     int
+		expectedRetryAfter string
+	}{
+		{"first login", 0, "/login", http.MethodPost, "192.0.2.1:1000", http.StatusOK, ""},
+		{"second login from another port", 0, "/login", http.MethodPost, "192.0.2.1:2000", http.StatusOK, ""},
+		{"third login", 0, "/login", http.MethodPost, "192.0.2.1:1000", http.StatusTooManyRequests, "30"},
+		{"another client", 0, "/login", http.MethodPost, "192.0.2.2:1000", http.StatusOK, ""},
+		{"still limited", 20 * time.Second, "/login", http.MethodPost, "192.0.2.1:1000", http.StatusTooManyRequests, "10"},
+		{"refilled", 10 * time.Second, "/login", http.MethodPost, "192.0.2.1:1000", http.StatusOK, ""},
+		{"first report", 0, "/events/1/reports/1", http.MethodGet, "192.0.2.1:1000", http.StatusOK, ""},
+		{"same event", 0, "/events/1/reports/2", http.MethodGet, "192.0.2.2:1000", http.StatusTooManyRequests, "1"},
+		{"another event", 0, "/events/2/reports/1", http.MethodGet, "192.0.2.1:1000", http.StatusOK, ""},
+		{"event refilled", time.Second, "/events/1/reports/2", http.MethodGet, "192.0.2.1:1000", http.StatusOK, ""},
+	}
+
+	for _, step := range steps {
+		clock.now = clock.now.Add(step.advance)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(step.requestMethod, step.requestURL, nil)
+		req.RemoteAddr = step.remoteAddr
+		mux.ServeHTTP(rw, req)
+
+		if rw.Code != step.expectedStatus {
+			t.Errorf("%s: expected status %d, got %d", step.name, step.expectedStatus, rw.Code)
+		}
+
+		if retryAfter := rw.Header().Get("Retry-After"); retryAfter != step.expectedRetryAfter {
+			t.Errorf("%s: expected Retry-After %q, got %q", step.name, step.expectedRetryAfter, retryAfter)
+		}
+	}
+}
+
+func TestRateLimitGroupAndRoute(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	api := NewRateLimiter(3, time.Hour, RemoteIP)
+	api.now = clock.Now
+
+	exports := NewRateLimiter(1, time.Hour, RemoteIP)
+	exports.now = clock.Now
+
+	mux := new(ServeMux)
+	group := mux.Group("/api", RateLimit(api))
+	group.Handle("/export", http.MethodPost, stringHandler("export"), RateLimit(exports))
+	group.Handle("/posts", http.MethodGet, stringHandler("posts"))
+
+	steps := []struct {
+		requestURL     string
+		requestMethod  string
+		expectedStatus int
+	}{
+		{"/api/export", http.MethodPost, http.StatusOK},
+		{"/api/export", http.MethodPost, http.StatusTooManyRequests},
+		{"/api/export", http.MethodPost, http.StatusTooManyRequests},
+		// the rejected exports don't count against the group limit.
+		{"/api/posts", http.MethodGet, http.StatusOK},
+		{"/api/posts", http.MethodGet, http.StatusOK},
+		{"/api/posts", http.MethodGet, http.StatusTooManyRequests},
+	}
+
+	for i, step := range steps {
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, httptest.NewRequest(step.requestMethod, step.requestURL, nil))
+
+		if rw.Code != step.expectedStatus {
+			t.Errorf("request %d to %s: expected status %d, got %d", i, step.requestURL, step.expectedStatus, rw.Code)
+		}
+	}
+}
+
+func TestTooManyRequestsHandler(t *testing.T) {
+	limiter := NewRateLimiter(1, time.Hour, func(r *http.Request) string { return "all" })
+
+	mux := &ServeMux{TooManyRequestsHandler: stringHandler("slow down")}
+	mux.Handle("/", http.MethodGet, stringHandler("root"), RateLimit(limiter))
+
+	for _, expected := range []string{"root", "slow down"} {
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if body := rw.Body.String(); body != expected {
+			t.Errorf("expected response body %q, got %q", expected, body)
+		}
+	}
+}
+
+func TestRateLimiterEmptyKey(t *testing.T) {
+	limiter := NewRateLimiter(1, time.Hour, func(r *http.Request) string { return r.Header.Get("X-Api-Key") })
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.Allow(httptest.NewRequest(http.MethodGet, "/", nil)); !ok {
+			t.Errorf("expected requests without a key not to be limited")
+		}
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	limiter := NewRateLimiter(1, time.Minute, RemoteIP)
+	limiter.now = clock.Now
+
+	for _, addr := range []string{"192.0.2.1:1", "192.0.2.2:1", "192.0.2.3:1"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = addr
+		limiter.Allow(req)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.0.2.4:1"
+	limiter.Allow(req)
+
+	if len(limiter.keys) != 1 {
+		t.Errorf("expected idle buckets to be removed, got %d buckets", len(limiter.keys))
+	}
+}
+
+func TestRateLimiterConcurrent(t *testing.T) {
+	limiter := NewRateLimiter(50, time.Hour, func(r *http.Request) string { return "all" })
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if ok, _ := limiter.Allow(httptest.NewRequest(http.MethodGet, "/", nil)); ok {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if allowed != 50 {
+		t.Errorf("expected 50 requests to be allowed, got %d", allowed)
+	}
+}
+
+func TestNewRateLimiterPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for a zero limit")
+		}
+	}()
+
+	NewRateLimiter(0, time.Second, RemoteIP)
+}
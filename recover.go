@@ -0,0 +1,42 @@
+package gemux
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// handlePanic replies to a request whose handler panicked with value v, using
+// the PanicHandler of the mux if it is set. http.ErrAbortHandler is never
+// recovered from, so handlers can still abort a response.
+func (mux *ServeMux) handlePanic(w *responseWriter, r *http.Request, v interface{}) {
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+
+	if mux.PanicHandler != nil {
+		mux.PanicHandler(w.writer, r, v)
+		return
+	}
+
+	logf(r, "gemux: panic serving %s %s: %v\n%s", r.Method, r.URL.Path, v, debug.Stack())
+
+	if w.wroteHeader() {
+		// the response can't be turned into an error, so abort it to make sure
+		// the client doesn't mistake it for a complete one.
+		panic(http.ErrAbortHandler)
+	}
+
+	http.Error(w, "500 internal server error", http.StatusInternalServerError)
+}
+
+// logf logs to the ErrorLog of the http.Server serving the request if there is
+// one, otherwise to the standard logger.
+func logf(r *http.Request, format string, args ...interface{}) {
+	if server, ok := r.Context().Value(http.ServerContextKey).(*http.Server); ok && server.ErrorLog != nil {
+		server.ErrorLog.Printf(format, args...)
+		return
+	}
+
+	log.Printf(format, args...)
+}
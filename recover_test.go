@@ -0,0 +1,120 @@
+package gemux
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func panicHandler(v interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(v)
+	})
+}
+
+// newLoggedRequest returns a request served by an http.Server logging to buf.
+func newLoggedRequest(method, target string, buf *bytes.Buffer) *http.Request {
+	server := &http.Server{ErrorLog: log.New(buf, "", 0)}
+	req := httptest.NewRequest(method, target, nil)
+	return req.WithContext(context.WithValue(req.Context(), http.ServerContextKey, server))
+}
+
+func TestPanicDefault(t *testing.T) {
+	var logged bytes.Buffer
+
+	mux := new(ServeMux)
+	mux.Handle("/foo", http.MethodGet, panicHandler("oops"))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, newLoggedRequest(http.MethodGet, "/foo", &logged))
+
+	if rw.Code != http.StatusInternalServerError {
+		t.Errorf("expected response code %d, got %d", http.StatusInternalServerError, rw.Code)
+	}
+
+	if body := rw.Body.String(); body != "500 internal server error\n" {
+		t.Errorf("expected response body %q, got %q", "500 internal server error\n", body)
+	}
+
+	if !strings.Contains(logged.String(), "gemux: panic serving GET /foo: oops") {
+		t.Errorf("expected panic to be logged, got %q", logged.String())
+	}
+}
+
+func TestPanicAfterWrite(t *testing.T) {
+	var logged bytes.Buffer
+
+	mux := new(ServeMux)
+	mux.Handle("/foo", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "partial")
+		panic("oops")
+	}))
+
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("expected panic with http.ErrAbortHandler, got %v", recovered)
+		}
+	}()
+
+	mux.ServeHTTP(httptest.NewRecorder(), newLoggedRequest(http.MethodGet, "/foo", &logged))
+}
+
+func TestPanicAbortHandler(t *testing.T) {
+	called := false
+
+	mux := new(ServeMux)
+	mux.PanicHandler = func(w http.ResponseWriter, r *http.Request, v interface{}) {
+		called = true
+	}
+	mux.Handle("/foo", http.MethodGet, panicHandler(http.ErrAbortHandler))
+
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("expected panic with http.ErrAbortHandler, got %v", recovered)
+		}
+
+		if called {
+			t.Errorf("expected PanicHandler not to be called")
+		}
+	}()
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/foo", nil))
+}
+
+func TestPanicHandler(t *testing.T) {
+	mux := new(ServeMux)
+	mux.PanicHandler = func(w http.ResponseWriter, r *http.Request, v interface{}) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, v.(string)+" "+PathParameter(r.Context(), 0))
+	}
+	mux.Handle("/foo/*", http.MethodGet, panicHandler("oops"))
+	mux.NotFoundHandler = panicHandler("not found")
+
+	cases := []struct {
+		requestURL           string
+		expectedResponseBody string
+	}{
+		{"/foo/bar", "oops bar"},
+		{"/baz", "not found "},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.requestURL, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, tt.requestURL, nil))
+
+			if rw.Code != http.StatusServiceUnavailable {
+				t.Errorf("expected response code %d, got %d", http.StatusServiceUnavailable, rw.Code)
+			}
+
+			if body := rw.Body.String(); body != tt.expectedResponseBody {
+				t.Errorf("expected response body %q, got %q", tt.expectedResponseBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package gemux
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDConfig configures the request ID middleware returned by RequestID.
+type RequestIDConfig struct {
+	// Header is the header the request ID is read from and echoed on. If
+	// Header is empty, "X-Request-ID" is used.
+	Header string
+
+	// Generate returns a new request ID for requests without a valid one. If
+	// Generate is nil, 16 random bytes encoded as hex are used.
+	Generate func() string
+}
+
+// maxRequestIDLength is the length of the longest request ID that is accepted
+// from a request.
+const maxRequestIDLength = 128
+
+// RequestID returns middleware that gives each request an ID, which is read
+// from the request header if it is valid, or generated otherwise. Request IDs
+// are valid if they are at most 128 printable ASCII characters long. The ID is
+// stored in the context of the request, where it can be retrieved with
+// RequestIDFromContext, and echoed on the response header. When added with Use,
+// the ID is also set on the RoutingError passed to ErrorHandler.
+func RequestID(config RequestIDConfig) func(http.Handler) http.Handler {
+	header := config.Header
+	if header == "" {
+		header = "X-Request-ID"
+	}
+
+	generate := config.Generate
+	if generate == nil {
+		generate = generateRequestID
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(header)
+			if !validRequestID(id) {
+				id = generate()
+			}
+
+			w.Header().Set(header, id)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+		})
+	}
+}
+
+// RequestIDFromContext returns the request ID stored in the context by the
+// RequestID middleware, or an empty string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// validRequestID reports whether id can be used as a request ID.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// generateRequestID returns 16 random bytes encoded as hex.
+func generateRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("gemux: failed to generate request ID: " + err.Error())
+	}
+
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,144 @@
+package gemux
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	cases := []struct {
+		name               string
+		config             RequestIDConfig
+		requestURL         string
+		requestMethod      string
+		requestHeader      http.Header
+		expectedRequestID  string
+		expectedHeaderName string
+		expectedBody       string
+	}{
+		{
+			name:               "generated",
+			config:             RequestIDConfig{Generate: func() string { return "generated" }},
+			requestURL:         "/posts",
+			requestMethod:      http.MethodGet,
+			expectedRequestID:  "generated",
+			expectedHeaderName: "X-Request-ID",
+			expectedBody:       "posts generated",
+		},
+		{
+			name:               "incoming",
+			config:             RequestIDConfig{Generate: func() string { return "generated" }},
+			requestURL:         "/posts",
+			requestMethod:      http.MethodGet,
+			requestHeader:      http.Header{"X-Request-Id": {"incoming-1"}},
+			expectedRequestID:  "incoming-1",
+			expectedHeaderName: "X-Request-ID",
+			expectedBody:       "posts incoming-1",
+		},
+		{
+			name:               "invalid incoming",
+			config:             RequestIDConfig{Generate: func() string { return "generated" }},
+			requestURL:         "/posts",
+			requestMethod:      http.MethodGet,
+			requestHeader:      http.Header{"X-Request-Id": {"bad id"}},
+			expectedRequestID:  "generated",
+			expectedHeaderName: "X-Request-ID",
+			expectedBody:       "posts generated",
+		},
+		{
+			name:               "custom header",
+			config:             RequestIDConfig{Header: "X-Correlation-ID", Generate: func() string { return "generated" }},
+			requestURL:         "/posts",
+			requestMethod:      http.MethodGet,
+			requestHeader:      http.Header{"X-Correlation-Id": {"correlated"}},
+			expectedRequestID:  "correlated",
+			expectedHeaderName: "X-Correlation-ID",
+			expectedBody:       "posts correlated",
+		},
+		{
+			name:               "not found",
+			config:             RequestIDConfig{Generate: func() string { return "generated" }},
+			requestURL:         "/users",
+			requestMethod:      http.MethodGet,
+			expectedRequestID:  "generated",
+			expectedHeaderName: "X-Request-ID",
+			expectedBody:       "not found generated",
+		},
+		{
+			name:               "method not allowed",
+			config:             RequestIDConfig{Generate: func() string { return "generated" }},
+			requestURL:         "/posts",
+			requestMethod:      http.MethodPut,
+			expectedRequestID:  "generated",
+			expectedHeaderName: "X-Request-ID",
+			expectedBody:       "method not allowed generated",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := new(ServeMux)
+			mux.Use(RequestID(tt.config))
+			mux.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err *RoutingError) {
+				w.WriteHeader(err.Status())
+				_, _ = io.WriteString(w, err.Kind.String()+" "+err.RequestID)
+			}
+
+			mux.Handle("/posts", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.WriteString(w, "posts "+RequestIDFromContext(r.Context()))
+			}))
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.requestMethod, tt.requestURL, nil)
+			for key, values := range tt.requestHeader {
+				req.Header[key] = values
+			}
+
+			mux.ServeHTTP(rw, req)
+
+			if id := rw.Header().Get(tt.expectedHeaderName); id != tt.expectedRequestID {
+				t.Errorf("expected %s header %q, got %q", tt.expectedHeaderName, tt.expectedRequestID, id)
+			}
+
+			if body := rw.Body.String(); body != tt.expectedBody {
+				t.Errorf("expected response body %q, got %q", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestGenerateRequestID(t *testing.T) {
+	a, b := generateRequestID(), generateRequestID()
+
+	if len(a) != 32 || !validRequestID(a) {
+		t.Errorf("expected 32 hex characters, got %q", a)
+	}
+
+	if a == b {
+		t.Errorf("expected different request IDs, got %q twice", a)
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	cases := []struct {
+		id       string
+		expected bool
+	}{
+		{"abc-123", true},
+		{strings.Repeat("a", maxRequestIDLength), true},
+		{strings.Repeat("a", maxRequestIDLength+1), false},
+		{"", false},
+		{"with space", false},
+		{"new\nline", false},
+		{"café", false},
+	}
+
+	for _, tt := range cases {
+		if actual := validRequestID(tt.id); actual != tt.expected {
+			t.Errorf("expected validRequestID(%q) to be %t, got %t", tt.id, tt.expected, actual)
+		}
+	}
+}
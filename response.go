@@ -0,0 +1,252 @@
+package gemux
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// responseWriter wraps an http.ResponseWriter to record the status code and
+// the number of bytes written. Handlers are given its writer, which implements
+// the optional http.Flusher, http.Hijacker, http.Pusher and io.ReaderFrom
+// interfaces only if the wrapped ResponseWriter does, so type assertions for
+// them behave as they would without the mux.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+	writer http.ResponseWriter // rw with the optional interfaces of ResponseWriter
+}
+
+// newResponseWriter wraps w, unless it is already wrapped.
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	if wrapped, ok := w.(interface{ recorder() *responseWriter }); ok {
+		return wrapped.recorder()
+	}
+
+	rw := &responseWriter{ResponseWriter: w}
+	rw.writer = rw.withInterfaces()
+	return rw
+}
+
+// recorder returns rw, so writers that embed it can be recognised as already
+// wrapped.
+func (rw *responseWriter) recorder() *responseWriter {
+	return rw
+}
+
+// wroteHeader reports whether the header has been written.
+func (rw *responseWriter) wroteHeader() bool {
+	return rw.status != 0
+}
+
+// statusCode returns the status code written, or http.StatusOK if the header
+// hasn't been written, which is the status net/http replies with.
+func (rw *responseWriter) statusCode() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+
+	return rw.status
+}
+
+// WriteHeader records the status code and writes it to the wrapped
+// ResponseWriter.
+func (rw *responseWriter) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes b to the wrapped ResponseWriter, recording the number of bytes
+// written and an implicit 200 status code if the header hasn't been written yet.
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap returns the wrapped ResponseWriter, for use by http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// withInterfaces returns rw combined with the implementations of each optional
+// interface the wrapped ResponseWriter implements.
+func (rw *responseWriter) withInterfaces() http.ResponseWriter {
+	var kind int
+	if _, ok := rw.ResponseWriter.(http.Flusher); ok {
+		kind |= 1
+	}
+
+	if _, ok := rw.ResponseWriter.(http.Hijacker); ok {
+		kind |= 2
+	}
+
+	if _, ok := rw.ResponseWriter.(http.Pusher); ok {
+		kind |= 4
+	}
+
+	if _, ok := rw.ResponseWriter.(io.ReaderFrom); ok {
+		kind |= 8
+	}
+
+	f, h, p, rf := flusher{rw}, hijacker{rw}, pusher{rw}, readerFrom{rw}
+
+	switch kind {
+	case 1:
+		return struct {
+			*responseWriter
+			flusher
+		}{rw, f}
+	case 2:
+		return struct {
+			*responseWriter
+			hijacker
+		}{rw, h}
+	case 3:
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+		}{rw, f, h}
+	case 4:
+		return struct {
+			*responseWriter
+			pusher
+		}{rw, p}
+	case 5:
+		return struct {
+			*responseWriter
+			flusher
+			pusher
+		}{rw, f, p}
+	case 6:
+		return struct {
+			*responseWriter
+			hijacker
+			pusher
+		}{rw, h, p}
+	case 7:
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			pusher
+		}{rw, f, h, p}
+	case 8:
+		return struct {
+			*responseWriter
+			readerFrom
+		}{rw, rf}
+	case 9:
+		return struct {
+			*responseWriter
+			flusher
+			readerFrom
+		}{rw, f, rf}
+	case 10:
+		return struct {
+			*responseWriter
+			hijacker
+			readerFrom
+		}{rw, h, rf}
+	case 11:
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			readerFrom
+		}{rw, f, h, rf}
+	case 12:
+		return struct {
+			*responseWriter
+			pusher
+			readerFrom
+		}{rw, p, rf}
+	case 13:
+		return struct {
+			*responseWriter
+			flusher
+			pusher
+			readerFrom
+		}{rw, f, p, rf}
+	case 14:
+		return struct {
+			*responseWriter
+			hijacker
+			pusher
+			readerFrom
+		}{rw, h, p, rf}
+	case 15:
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+			pusher
+			readerFrom
+		}{rw, f, h, p, rf}
+	}
+
+	return rw
+}
+
+// flusher implements http.Flusher for a responseWriter wrapping a Flusher.
+type flusher struct{ rw *responseWriter }
+
+// Flush flushes the wrapped ResponseWriter, recording an implicit 200 status
+// code if the header hasn't been written yet.
+func (f flusher) Flush() {
+	if f.rw.status == 0 {
+		f.rw.status = http.StatusOK
+	}
+
+	f.rw.ResponseWriter.(http.Flusher).Flush()
+}
+
+// hijacker implements http.Hijacker for a responseWriter wrapping a Hijacker.
+type hijacker struct{ rw *responseWriter }
+
+// Hijack hijacks the connection of the wrapped ResponseWriter, recording a 101
+// status code if the header hasn't been written yet.
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := h.rw.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil && h.rw.status == 0 {
+		h.rw.status = http.StatusSwitchingProtocols
+	}
+
+	return conn, buf, err
+}
+
+// pusher implements http.Pusher for a responseWriter wrapping a Pusher.
+type pusher struct{ rw *responseWriter }
+
+// Push pushes target with the wrapped ResponseWriter.
+func (p pusher) Push(target string, opts *http.PushOptions) error {
+	return p.rw.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+// readerFrom implements io.ReaderFrom for a responseWriter wrapping a
+// ReaderFrom, so responses such as those of http.ServeContent can still be
+// sent with sendfile.
+type readerFrom struct{ rw *responseWriter }
+
+// ReadFrom copies src to the wrapped ResponseWriter, recording the number of
+// bytes written and an implicit 200 status code if the header hasn't been
+// written yet.
+func (rf readerFrom) ReadFrom(src io.Reader) (int64, error) {
+	if rf.rw.status == 0 {
+		rf.rw.status = http.StatusOK
+	}
+
+	n, err := rf.rw.ResponseWriter.(io.ReaderFrom).ReadFrom(src)
+	rf.rw.bytes += n
+	return n, err
+}
@@ -0,0 +1,161 @@
+package gemux
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// readerFromRecorder is a ResponseRecorder that implements io.ReaderFrom.
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+	readFrom bool
+}
+
+func (rec *readerFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	rec.readFrom = true
+	return io.Copy(rec.ResponseRecorder, src)
+}
+
+func TestResponseWriterInterfaces(t *testing.T) {
+	cases := []struct {
+		name             string
+		writer           http.ResponseWriter
+		expectFlusher    bool
+		expectHijacker   bool
+		expectPusher     bool
+		expectReaderFrom bool
+	}{
+		{
+			name:          "recorder",
+			writer:        httptest.NewRecorder(),
+			expectFlusher: true,
+		},
+		{
+			name:   "no optional interfaces",
+			writer: struct{ http.ResponseWriter }{httptest.NewRecorder()},
+		},
+		{
+			name:             "reader from",
+			writer:           &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()},
+			expectFlusher:    true,
+			expectReaderFrom: true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := new(ServeMux)
+			mux.Handle("/", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if _, ok := w.(http.Flusher); ok != tt.expectFlusher {
+					t.Errorf("expected http.Flusher to be implemented: %v", tt.expectFlusher)
+				}
+
+				if _, ok := w.(http.Hijacker); ok != tt.expectHijacker {
+					t.Errorf("expected http.Hijacker to be implemented: %v", tt.expectHijacker)
+				}
+
+				if _, ok := w.(http.Pusher); ok != tt.expectPusher {
+					t.Errorf("expected http.Pusher to be implemented: %v", tt.expectPusher)
+				}
+
+				if _, ok := w.(io.ReaderFrom); ok != tt.expectReaderFrom {
+					t.Errorf("expected io.ReaderFrom to be implemented: %v", tt.expectReaderFrom)
+				}
+
+				if flusher, ok := w.(http.Flusher); ok {
+					flusher.Flush()
+				}
+
+				if readerFrom, ok := w.(io.ReaderFrom); ok {
+					if n, err := readerFrom.ReadFrom(strings.NewReader("abc")); n != 3 || err != nil {
+						t.Errorf("expected to read 3 bytes, got %d and %v", n, err)
+					}
+				}
+			}))
+
+			mux.ServeHTTP(tt.writer, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec, ok := tt.writer.(*httptest.ResponseRecorder); ok && !rec.Flushed {
+				t.Errorf("expected response to be flushed")
+			}
+
+			if rec, ok := tt.writer.(*readerFromRecorder); ok {
+				if !rec.readFrom {
+					t.Errorf("expected ReadFrom to be forwarded")
+				}
+
+				if body := rec.Body.String(); body != "abc" {
+					t.Errorf("expected body %q, got %q", "abc", body)
+				}
+			}
+		})
+	}
+}
+
+func TestResponseWriterReadFrom(t *testing.T) {
+	rec := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	rw := newResponseWriter(rec)
+
+	if _, err := rw.writer.(io.ReaderFrom).ReadFrom(strings.NewReader("abc")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rw.status != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, rw.status)
+	}
+
+	if rw.bytes != 3 {
+		t.Errorf("expected 3 bytes, got %d", rw.bytes)
+	}
+
+	if newResponseWriter(rw.writer) != rw {
+		t.Errorf("expected the writer not to be wrapped again")
+	}
+}
+
+func TestResponseWriterStatus(t *testing.T) {
+	cases := []struct {
+		name           string
+		handler        http.HandlerFunc
+		expectedStatus int
+	}{
+		{
+			name:           "nothing written",
+			handler:        func(w http.ResponseWriter, r *http.Request) {},
+			expectedStatus: 0,
+		},
+		{
+			name: "explicit header",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				w.WriteHeader(http.StatusAccepted)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "implicit header",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("a"))
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := newResponseWriter(httptest.NewRecorder())
+			tt.handler(rw, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rw.status != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rw.status)
+			}
+
+			if rw.wroteHeader() != (tt.expectedStatus != 0) {
+				t.Errorf("expected wroteHeader to be %v", tt.expectedStatus != 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package gemux
+
+import (
+	"net/http"
+	"sort"
+	"time"
+)
+
+// A RouteOption configures a route registered with Handle.
+type RouteOption func(*route)
+
+// route is a handler registered for a pattern and method, along with the
+// predicates a request has to satisfy for the handler to be chosen and the
+// options it is served with.
+type route struct {
+	handler      http.Handler
+	method       string // method the route was registered with, "*" for any method
+	pattern      string // pattern the route was registered with, before cleaning
+	predicates   []predicate
+	produces     string            // media type of the response, used for content negotiation
+	timeout      time.Duration     // zero if the route has no timeout
+	maxBodyBytes int64             // zero to use the mux default, negative for no limit
+	cors         *CORSConfig       // nil to use the mux default
+	operation    *Operation        // nil if the route isn't described
+	paramNames   []string          // names of the path parameters, nil if they aren't named
+	rateLimiters []*RateLimiter    // limiters every request has to be allowed by
+	metadata     map[string]string // nil if the route has no metadata
+	description  *Route            // built when the route is registered, held by the context of its requests
+}
+
+// Route describes a handler registered on a ServeMux.
+type Route struct {
+	// Pattern is the cleaned pattern the route was registered with, such as
+	// "/posts/*/comments".
+	Pattern string
+
+	// Method is the method the route was registered with, or "*" for routes
+	// that match any method.
+	Method string
+
+	// Handler is the handler the route was registered with.
+	Handler http.Handler
+
+	// Operation is the metadata attached to the route with Describe, or nil if
+	// the route isn't described.
+	Operation *Operation
+
+	// ParamNames are the names given to the path parameters of the route with
+	// ParamNames, or nil if they aren't named.
+	ParamNames []string
+
+	// Metadata is the metadata attached to the route with the Metadata
+	// option, or nil if it has none. It is shared by every request to the
+	// route, so it must not be modified.
+	Metadata map[string]string
+}
+
+// ParamNames returns a RouteOption that names the path parameters of the route,
+// one name for each wildcard of the pattern in order, so they can be retrieved
+// with NamedPathParameter. Handle panics if the number of names doesn't match
+// the number of wildcards.
+func ParamNames(names ...string) RouteOption {
+	return func(rt *route) {
+		rt.paramNames = append([]string{}, names...)
+	}
+}
+
+// Metadata returns a RouteOption that attaches metadata to the route, such as
+// {"auth": "admin"} or {"deprecated": ""}, which is available to middleware and
+// handlers through RouteFromContext and to introspection through Routes. When
+// given several times, such as to a group and a route of the group, the
+// metadata is merged, with later values replacing earlier ones for the same
+// key.
+func Metadata(metadata map[string]string) RouteOption {
+	return func(rt *route) {
+		merged := make(map[string]string, len(rt.metadata)+len(metadata))
+		for key, value := range rt.metadata {
+			merged[key] = value
+		}
+
+		for key, value := range metadata {
+			merged[key] = value
+		}
+
+		rt.metadata = merged
+	}
+}
+
+// Routes returns every route registered on the mux, ordered by pattern and then
+// method, with wildcard path segments and methods ordered before the others.
+// Routes for the same pattern and method that differ by predicates are returned
+// in the order they are tried in.
+func (mux *ServeMux) Routes() []Route {
+	var routes []Route
+	mux.walk("", func(pattern string, node *ServeMux) {
+		for _, rt := range node.routes() {
+			routes = append(routes, rt.describe(pattern))
+		}
+	})
+
+	return routes
+}
+
+// walk calls fn for the mux and each of its descendants, in pattern order with
+// the wildcard child before static children.
+func (mux *ServeMux) walk(pattern string, fn func(pattern string, node *ServeMux)) {
+	if pattern == "" {
+		fn("/", mux)
+	} else {
+		fn(pattern, mux)
+	}
+
+	if mux.wildcardChild != nil {
+		mux.wildcardChild.walk(pattern+"/*", fn)
+	}
+
+	segments := make([]string, 0, len(mux.children))
+	for segment := range mux.children {
+		segments = append(segments, segment)
+	}
+
+	sort.Strings(segments)
+	for _, segment := range segments {
+		mux.children[segment].walk(pattern+"/"+segment, fn)
+	}
+}
+
+// describe returns the public description of the route.
+func (rt *route) describe(pattern string) Route {
+	return Route{
+		Pattern:    pattern,
+		Method:     rt.method,
+		Handler:    rt.handler,
+		Operation:  rt.operation,
+		ParamNames: rt.paramNames,
+		Metadata:   rt.metadata,
+	}
+}
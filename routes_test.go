@@ -0,0 +1,105 @@
+package gemux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRoutes(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Handle("/posts/*/comments", http.MethodGet, stringHandler("a"))
+	mux.Handle("/posts", http.MethodPost, stringHandler("b"))
+	mux.Handle("/posts", http.MethodGet, stringHandler("c"))
+	mux.Handle("/posts", "PROPFIND", stringHandler("d"))
+	mux.Handle("/posts/*", "*", stringHandler("e"))
+	mux.Handle("/", http.MethodGet, stringHandler("f"))
+	mux.Handle("/posts", http.MethodGet, stringHandler("g"), Query("version", "2"))
+
+	expected := []struct {
+		pattern string
+		method  string
+	}{
+		{"/", http.MethodGet},
+		{"/posts", http.MethodGet},
+		{"/posts", http.MethodGet},
+		{"/posts", http.MethodPost},
+		{"/posts", "PROPFIND"},
+		{"/posts/*", "*"},
+		{"/posts/*/comments", http.MethodGet},
+	}
+
+	routes := mux.Routes()
+	if len(routes) != len(expected) {
+		t.Fatalf("expected %d routes, got %d: %v", len(expected), len(routes), routes)
+	}
+
+	for i, route := range routes {
+		if route.Pattern != expected[i].pattern || route.Method != expected[i].method {
+			t.Errorf("expected route %d to be %s %s, got %s %s", i, expected[i].method, expected[i].pattern,
+				route.Method, route.Pattern)
+		}
+
+		if route.Handler == nil {
+			t.Errorf("expected route %d to have a handler", i)
+		}
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	mux := new(ServeMux)
+	mux.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rt, _ := RouteFromContext(r.Context()); rt.Metadata["auth"] == "admin" && r.Header.Get("X-Admin") == "" {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	admin := mux.Group("/admin", Metadata(map[string]string{"auth": "admin"}))
+	admin.Handle("/users", http.MethodGet, stringHandler("users"))
+	admin.Handle("/legacy", http.MethodGet, stringHandler("legacy"), Metadata(map[string]string{"deprecated": ""}))
+	mux.Handle("/health", http.MethodGet, stringHandler("ok"), Metadata(map[string]string{"public": ""}))
+	mux.Handle("/status", http.MethodGet, stringHandler("status"),
+		Metadata(map[string]string{"auth": "admin"}), Metadata(map[string]string{"auth": "user"}))
+
+	expectedMetadata := map[string]map[string]string{
+		"/admin/legacy": {"auth": "admin", "deprecated": ""},
+		"/admin/users":  {"auth": "admin"},
+		"/health":       {"public": ""},
+		"/status":       {"auth": "user"},
+	}
+
+	for _, route := range mux.Routes() {
+		if !reflect.DeepEqual(route.Metadata, expectedMetadata[route.Pattern]) {
+			t.Errorf("expected metadata %v for %s, got %v", expectedMetadata[route.Pattern], route.Pattern, route.Metadata)
+		}
+
+		if _, public := route.Metadata["public"]; !public && route.Metadata["auth"] == "" {
+			t.Errorf("expected non-public route %s to have an auth tag", route.Pattern)
+		}
+	}
+
+	cases := []struct {
+		requestURL     string
+		expectedStatus int
+	}{
+		{"/admin/users", http.StatusForbidden},
+		{"/admin/legacy", http.StatusForbidden},
+		{"/health", http.StatusOK},
+		{"/status", http.StatusOK},
+	}
+
+	for _, tt := range cases {
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, tt.requestURL, nil))
+
+		if rw.Code != tt.expectedStatus {
+			t.Errorf("expected status %d for %s, got %d", tt.expectedStatus, tt.requestURL, rw.Code)
+		}
+	}
+}
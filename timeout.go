@@ -0,0 +1,138 @@
+package gemux
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Timeout returns a RouteOption that limits how long the handler of the route
+// has to serve a request. The request context is given a deadline of d, and if
+// the handler hasn't returned by then the mux replies with the TimeoutHandler of
+// the mux. Writes the handler makes after the deadline fail with
+// http.ErrHandlerTimeout.
+//
+// Like http.TimeoutHandler, the response is buffered until the handler returns,
+// so the ResponseWriter doesn't support flushing or hijacking.
+func Timeout(d time.Duration) RouteOption {
+	return func(rt *route) {
+		rt.timeout = d
+	}
+}
+
+// timeoutHandler returns the mux TimeoutHandler if there is one, otherwise
+// TimeoutHandler.
+func (mux *ServeMux) timeoutHandler() http.Handler {
+	if mux.TimeoutHandler != nil {
+		return mux.TimeoutHandler
+	}
+
+	return TimeoutHandler()
+}
+
+// TimeoutHandler returns a simple request handler that replies to each request
+// with a "503 service unavailable" reply and writes the 503 status code.
+func TimeoutHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "503 service unavailable", http.StatusServiceUnavailable)
+	})
+}
+
+// serveWithTimeout serves the request with handler, replying with the timeout
+// handler if it doesn't return within timeout. A panic in handler is propagated
+// to the calling goroutine, so it can be recovered from there.
+func (mux *ServeMux) serveWithTimeout(w http.ResponseWriter, r *http.Request, handler http.Handler, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	r = r.WithContext(ctx)
+
+	done := make(chan struct{})
+	panicked := make(chan interface{}, 1)
+	tw := &timeoutWriter{header: make(http.Header)}
+
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				panicked <- v
+			}
+		}()
+
+		handler.ServeHTTP(tw, r)
+		close(done)
+	}()
+
+	select {
+	case v := <-panicked:
+		panic(v)
+	case <-done:
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+
+		dst := w.Header()
+		for key, values := range tw.header {
+			dst[key] = values
+		}
+
+		if tw.status == 0 {
+			tw.status = http.StatusOK
+		}
+
+		w.WriteHeader(tw.status)
+		_, _ = w.Write(tw.body.Bytes())
+	case <-ctx.Done():
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+
+		tw.timedOut = true
+		if ctx.Err() == context.DeadlineExceeded {
+			mux.timeoutHandler().ServeHTTP(w, r)
+		}
+	}
+}
+
+// timeoutWriter buffers the response of a handler with a timeout, so it can be
+// discarded if the handler times out.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+// Header returns the buffered header.
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// Write buffers b, or returns http.ErrHandlerTimeout if the handler timed out.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+
+	return tw.body.Write(b)
+}
+
+// WriteHeader buffers the status code, unless the handler timed out or the
+// status code was already written.
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.status != 0 {
+		return
+	}
+
+	tw.status = status
+}
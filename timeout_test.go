@@ -0,0 +1,82 @@
+package gemux
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	writeErr := make(chan error, 1)
+	release := make(chan struct{})
+
+	mux := new(ServeMux)
+	mux.Handle("/fast", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Foo", "bar")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, "fast")
+	}), Timeout(time.Second))
+	mux.Handle("/slow", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		<-release
+		_, err := io.WriteString(w, "slow")
+		writeErr <- err
+	}), Timeout(10*time.Millisecond))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/fast", nil))
+
+	if rw.Code != http.StatusCreated || rw.Body.String() != "fast" || rw.Header().Get("X-Foo") != "bar" {
+		t.Errorf("expected buffered response to be written, got %d %q %v", rw.Code, rw.Body.String(), rw.Header())
+	}
+
+	rw = httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	close(release)
+
+	if rw.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected response code %d, got %d", http.StatusServiceUnavailable, rw.Code)
+	}
+
+	if body := rw.Body.String(); body != "503 service unavailable\n" {
+		t.Errorf("expected response body %q, got %q", "503 service unavailable\n", body)
+	}
+
+	if err := <-writeErr; err != http.ErrHandlerTimeout {
+		t.Errorf("expected write after timeout to fail with %v, got %v", http.ErrHandlerTimeout, err)
+	}
+}
+
+func TestTimeoutCustomHandler(t *testing.T) {
+	mux := new(ServeMux)
+	mux.TimeoutHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "took too long", http.StatusGatewayTimeout)
+	})
+	mux.Handle("/slow", http.MethodGet, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}), Timeout(time.Millisecond))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	if rw.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected response code %d, got %d", http.StatusGatewayTimeout, rw.Code)
+	}
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	mux := new(ServeMux)
+	mux.PanicHandler = func(w http.ResponseWriter, r *http.Request, v interface{}) {
+		http.Error(w, v.(string), http.StatusInternalServerError)
+	}
+	mux.Handle("/panic", http.MethodGet, panicHandler("oops"), Timeout(time.Second))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if rw.Code != http.StatusInternalServerError || rw.Body.String() != "oops\n" {
+		t.Errorf("expected panic to be handled by PanicHandler, got %d %q", rw.Code, rw.Body.String())
+	}
+}
@@ -0,0 +1,123 @@
+package gemux
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteTree writes the routing tree of the mux to w as indented text, one node
+// per line. Each line has the path segment of the node, which is "*" for
+// wildcards, followed by the methods registered on the node in brackets, with
+// "*" for routes that match any method. Wildcard children are written before
+// static children, in the order they are matched.
+//
+//	/ [GET]
+//	  posts [GET POST]
+//	    * [* GET]
+//	      comments [GET]
+func (mux *ServeMux) WriteTree(w io.Writer) error {
+	var buf bytes.Buffer
+
+	mux.visit(func(segment string, node *ServeMux, depth, parent int) {
+		buf.WriteString(strings.Repeat("  ", depth) + segment)
+		if methods := node.methods(); len(methods) > 0 {
+			buf.WriteString(" [" + strings.Join(methods, " ") + "]")
+		}
+
+		buf.WriteByte('\n')
+	})
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// WriteDOT writes the routing tree of the mux to w as a Graphviz DOT digraph.
+// Each node is labelled with its path segment and the methods registered on
+// it. Wildcard path segments are drawn dashed, and nodes with routes that match
+// any method are drawn with a double border.
+func (mux *ServeMux) WriteDOT(w io.Writer) error {
+	var buf bytes.Buffer
+
+	buf.WriteString("digraph gemux {\n\tnode [shape=box];\n")
+
+	id := 0
+	mux.visit(func(segment string, node *ServeMux, depth, parent int) {
+		label := dotEscape(segment)
+		if methods := node.methods(); len(methods) > 0 {
+			label += `\n` + dotEscape(strings.Join(methods, " "))
+		}
+
+		buf.WriteString("\tn" + strconv.Itoa(id) + ` [label="` + label + `"`)
+		if segment == "*" {
+			buf.WriteString(", style=dashed")
+		}
+
+		if len(node.wildcardRoutes) > 0 {
+			buf.WriteString(", peripheries=2")
+		}
+
+		buf.WriteString("];\n")
+
+		if parent >= 0 {
+			buf.WriteString("\tn" + strconv.Itoa(parent) + " -> n" + strconv.Itoa(id) + ";\n")
+		}
+
+		id++
+	})
+
+	buf.WriteString("}\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// visit calls fn for the mux and each of its descendants in the order of walk,
+// with the path segment of each node, which is "/" for the mux itself, its depth
+// and the index of its parent in the order fn is called, which is -1 for the
+// mux itself.
+func (mux *ServeMux) visit(fn func(segment string, node *ServeMux, depth, parent int)) {
+	n := 0
+
+	var visit func(segment string, node *ServeMux, depth, parent int)
+	visit = func(segment string, node *ServeMux, depth, parent int) {
+		index := n
+		n++
+
+		fn(segment, node, depth, parent)
+
+		if node.wildcardChild != nil {
+			visit("*", node.wildcardChild, depth+1, index)
+		}
+
+		segments := make([]string, 0, len(node.children))
+		for segment := range node.children {
+			segments = append(segments, segment)
+		}
+
+		sort.Strings(segments)
+		for _, segment := range segments {
+			visit(segment, node.children[segment], depth+1, index)
+		}
+	}
+
+	visit("/", mux, 0, -1)
+}
+
+// methods returns the methods registered on the mux, with "*" first if any
+// routes match every method.
+func (mux *ServeMux) methods() []string {
+	methods := mux.allowedMethods()
+	if len(mux.wildcardRoutes) > 0 {
+		methods = append([]string{"*"}, methods...)
+	}
+
+	return methods
+}
+
+// dotEscape escapes s to be used in a quoted DOT string.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,68 @@
+package gemux
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func treeTestMux() *ServeMux {
+	mux := new(ServeMux)
+	mux.Handle("/", http.MethodGet, stringHandler("root"))
+	mux.Handle("/posts", http.MethodGet, stringHandler("get posts"))
+	mux.Handle("/posts", http.MethodPost, stringHandler("create post"))
+	mux.Handle("/posts/*", "*", stringHandler("any post"))
+	mux.Handle("/posts/*", http.MethodGet, stringHandler("get post"), Header("Accept", "application/json"))
+	mux.Handle("/posts/*/comments", http.MethodGet, stringHandler("get comments"))
+	mux.Handle(`/users/a"b/profile`, http.MethodGet, stringHandler("profile"))
+	return mux
+}
+
+func TestWriteTree(t *testing.T) {
+	var buf bytes.Buffer
+	if err := treeTestMux().WriteTree(&buf); err != nil {
+		t.Fatalf("did not expect error writing tree: %v", err)
+	}
+
+	expected := `/ [GET]
+  posts [GET POST]
+    * [* GET]
+      comments [GET]
+  users
+    a"b
+      profile [GET]
+`
+
+	if buf.String() != expected {
+		t.Errorf("expected tree:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := treeTestMux().WriteDOT(&buf); err != nil {
+		t.Fatalf("did not expect error writing DOT: %v", err)
+	}
+
+	expected := `digraph gemux {
+	node [shape=box];
+	n0 [label="/\nGET"];
+	n1 [label="posts\nGET POST"];
+	n0 -> n1;
+	n2 [label="*\n* GET", style=dashed, peripheries=2];
+	n1 -> n2;
+	n3 [label="comments\nGET"];
+	n2 -> n3;
+	n4 [label="users"];
+	n0 -> n4;
+	n5 [label="a\"b"];
+	n4 -> n5;
+	n6 [label="profile\nGET"];
+	n5 -> n6;
+}
+`
+
+	if buf.String() != expected {
+		t.Errorf("expected DOT:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}